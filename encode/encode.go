@@ -0,0 +1,102 @@
+// Package encode turns extracted site sequences into the numeric form
+// neural networks take: integer tokens or one-hot rows over A, C, G, T.
+package encode
+
+import "fmt"
+
+// Scheme selects how a sequence is encoded.
+type Scheme int
+
+const (
+	// OneHot gives every base a row of four values, one per A, C, G, T.
+	// Padding and ambiguous bases are all zero rows.
+	OneHot Scheme = iota
+	// Integer gives every base a single token; see the Pad..Other codes.
+	Integer
+)
+
+// Integer tokens. Pad fills positions past the end of a sequence and
+// Other stands for N and the other ambiguous IUPAC codes.
+const (
+	Pad uint8 = iota
+	A
+	C
+	G
+	T
+	Other
+)
+
+// Channels is the width of a one-hot row.
+const Channels = 4
+
+var tokens [256]uint8
+
+func init() {
+	for i := range tokens {
+		tokens[i] = Other
+	}
+	for b, t := range map[byte]uint8{'A': A, 'C': C, 'G': G, 'T': T, 'U': T} {
+		tokens[b] = t
+		tokens[b+'a'-'A'] = t
+	}
+}
+
+// String returns the name ParseScheme accepts.
+func (s Scheme) String() string {
+	if s == Integer {
+		return "integer"
+	}
+	return "onehot"
+}
+
+// ParseScheme accepts "onehot" or "integer".
+func ParseScheme(name string) (Scheme, error) {
+	switch name {
+	case "onehot", "one-hot":
+		return OneHot, nil
+	case "integer", "int":
+		return Integer, nil
+	}
+	return 0, fmt.Errorf("unknown encoding %q (want onehot or integer)", name)
+}
+
+// Token returns the integer token of base b.
+func Token(b byte) uint8 {
+	return tokens[b]
+}
+
+// Width is the number of values one base takes under the scheme.
+func (s Scheme) Width() int {
+	if s == Integer {
+		return 1
+	}
+	return Channels
+}
+
+// Append encodes seq into exactly length positions, padding short
+// sequences at the end and cutting long ones, and appends the
+// length*Width values to dst.
+func (s Scheme) Append(dst []uint8, seq string, length int) []uint8 {
+	return s.AppendMasked(dst, seq, nil, length)
+}
+
+// AppendMasked is Append with the positions whose mask is 0 encoded as
+// Pad. A nil mask keeps every base.
+func (s Scheme) AppendMasked(dst []uint8, seq string, mask []uint8, length int) []uint8 {
+	for i := 0; i < length; i++ {
+		t := Pad
+		if i < len(seq) && (mask == nil || mask[i] != 0) {
+			t = tokens[seq[i]]
+		}
+		if s == Integer {
+			dst = append(dst, t)
+			continue
+		}
+		var row [Channels]uint8
+		if t >= A && t <= T {
+			row[t-A] = 1
+		}
+		dst = append(dst, row[:]...)
+	}
+	return dst
+}
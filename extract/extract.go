@@ -0,0 +1,191 @@
+// Package extract cuts predicted target sites and their flanks out of
+// the target sequences.
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-microRNAs/fasta"
+	"github.com/go-microRNAs/predict"
+	"github.com/go-microRNAs/sequtil"
+	"github.com/go-microRNAs/window"
+)
+
+// Options control how much flanking sequence is taken around a site and
+// what happens when a flank runs off the end of its target.
+type Options struct {
+	Upstream   int
+	Downstream int
+	// Pad, when non-zero, fills the missing part of a short flank so
+	// every flank has its full length. Otherwise flanks are truncated.
+	Pad byte
+	// DropTruncated skips sites whose flanks could not be taken in full.
+	DropTruncated bool
+	// Window, when positive, also cuts every site with its flanks to
+	// that many bases centred on the cleavage position, or on the middle
+	// of the site when the tool gives none.
+	Window int
+}
+
+// Summary counts what happened to the sites of one run.
+type Summary struct {
+	Sites         int
+	Extracted     int
+	MissingTarget int
+	OutOfRange    int
+	Truncated     int
+	Dropped       int
+}
+
+// Flanks is a site with its flanking sequence. UpstreamPad and
+// DownstreamPad count the bases that lay beyond the target ends and were
+// padded, or left out when no pad character is set.
+type Flanks struct {
+	Site          string
+	Upstream      string
+	Downstream    string
+	UpstreamPad   int
+	DownstreamPad int
+}
+
+// Truncated reports whether either flank is short of the requested length.
+func (f Flanks) Truncated() bool {
+	return f.UpstreamPad > 0 || f.DownstreamPad > 0
+}
+
+// clamp limits [start, end) to [0, n) and returns how many bases were cut.
+func clamp(start, end, n int) (int, int, int) {
+	cut := 0
+	if start < 0 {
+		cut += -start
+		start = 0
+	}
+	if end > n {
+		cut += end - n
+		end = n
+	}
+	if start > end {
+		start = end
+	}
+	return start, end, cut
+}
+
+// Region fetches the site in [start, end) together with its upstream and
+// downstream flanks. Minus strand sites are reverse complemented and
+// their flanks swapped so all three read 5' to 3' on the strand the
+// miRNA pairs with, in the DNA alphabet. The site itself must lie within
+// the target; flanks are clamped to it.
+func Region(src fasta.Source, id string, start, end int, strand string, opt Options) (Flanks, error) {
+	n, ok := src.Length(id)
+	if !ok {
+		return Flanks{}, fmt.Errorf("%w: %s", fasta.ErrNotFound, id)
+	}
+	if start < 0 || end > n || start > end {
+		return Flanks{}, fmt.Errorf("%w: site %s:%d-%d on a target of %d bases", fasta.ErrRange, id, start, end, n)
+	}
+	upStart, upEnd := start-opt.Upstream, start
+	downStart, downEnd := end, end+opt.Downstream
+	if strand == "-" {
+		upStart, upEnd = end, end+opt.Upstream
+		downStart, downEnd = start-opt.Downstream, start
+	}
+	var f Flanks
+	upStart, upEnd, f.UpstreamPad = clamp(upStart, upEnd, n)
+	downStart, downEnd, f.DownstreamPad = clamp(downStart, downEnd, n)
+
+	var err error
+	if f.Site, err = sequtil.Fetch(src, id, start, end, strand); err != nil {
+		return f, err
+	}
+	if f.Upstream, err = sequtil.Fetch(src, id, upStart, upEnd, strand); err != nil {
+		return f, err
+	}
+	if f.Downstream, err = sequtil.Fetch(src, id, downStart, downEnd, strand); err != nil {
+		return f, err
+	}
+	// Missing upstream bases always lie 5' of the flank and missing
+	// downstream bases 3' of it, whichever strand the site is on.
+	if opt.Pad != 0 {
+		f.Upstream = strings.Repeat(string(opt.Pad), f.UpstreamPad) + f.Upstream
+		f.Downstream += strings.Repeat(string(opt.Pad), f.DownstreamPad)
+	}
+	return f, nil
+}
+
+// Sites fills in the sequence and flanks of every site whose target is
+// present in src. Sites on unknown targets or running past their target
+// are skipped, as are truncated ones when opt.DropTruncated is set; all
+// of these are counted in the summary.
+func Sites(src fasta.Source, sites []predict.TargetSite, opt Options) ([]predict.TargetSite, Summary, error) {
+	sum := Summary{Sites: len(sites)}
+	out := make([]predict.TargetSite, 0, len(sites))
+	for _, s := range sites {
+		f, err := Region(src, s.TargetID, s.Start, s.End, s.Strand, opt)
+		switch {
+		case errors.Is(err, fasta.ErrNotFound):
+			sum.MissingTarget++
+			continue
+		case errors.Is(err, fasta.ErrRange):
+			sum.OutOfRange++
+			continue
+		case err != nil:
+			return nil, sum, err
+		}
+		if f.Truncated() {
+			sum.Truncated++
+			if opt.DropTruncated {
+				sum.Dropped++
+				continue
+			}
+		}
+		s.Sequence, s.Upstream, s.Downstream = f.Site, f.Upstream, f.Downstream
+		s.UpstreamPad, s.DownstreamPad = f.UpstreamPad, f.DownstreamPad
+		if s.Features == nil {
+			s.Features = map[string]float64{}
+		} else {
+			s.Features = copyFeatures(s.Features)
+		}
+		s.Features["upstream_pad"] = float64(f.UpstreamPad)
+		s.Features["downstream_pad"] = float64(f.DownstreamPad)
+		if opt.Window > 0 {
+			parts := window.Parts{
+				Upstream:   s.Upstream,
+				Site:       s.Sequence,
+				Downstream: s.Downstream,
+				Center:     center(s),
+			}
+			// Pad characters in the flanks are padding in the window too.
+			if opt.Pad != 0 {
+				parts.UpstreamPad, parts.DownstreamPad = s.UpstreamPad, s.DownstreamPad
+			}
+			w := window.Cut(parts, opt.Window)
+			s.Window = &w
+		}
+		out = append(out, s)
+		sum.Extracted++
+	}
+	return out, sum, nil
+}
+
+// center returns the position in Upstream+Sequence+Downstream a window
+// is centred on: the base the target is cut before when the cleavage
+// position lies in the site, and the middle of the site otherwise.
+func center(s predict.TargetSite) int {
+	if s.Cleavage >= s.Start && s.Cleavage <= s.End {
+		if s.Strand == "-" {
+			return len(s.Upstream) + s.End - s.Cleavage
+		}
+		return len(s.Upstream) + s.Cleavage - s.Start
+	}
+	return len(s.Upstream) + len(s.Sequence)/2
+}
+
+func copyFeatures(m map[string]float64) map[string]float64 {
+	c := make(map[string]float64, len(m)+2)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+package extract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-microRNAs/fasta"
+	"github.com/go-microRNAs/predict"
+)
+
+// target is 16 bases whose runs make each flank easy to tell apart.
+var target = fasta.NewStore([]fasta.Record{{ID: "t", Seq: "ACGTAAACCCGGGTTT"}})
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		strand     string
+		pad        byte
+		want       Flanks
+	}{
+		{"plus", 6, 10, "+", 0, Flanks{Site: "ACCC", Upstream: "TAA", Downstream: "GG"}},
+		// Upstream of a minus strand site lies past its end on the plus strand.
+		{"minus", 6, 10, "-", 0, Flanks{Site: "GGGT", Upstream: "CCC", Downstream: "TT"}},
+		{"plus clamped", 1, 4, "+", 0, Flanks{Site: "CGT", Upstream: "A", Downstream: "AA", UpstreamPad: 2}},
+		{"plus padded", 1, 4, "+", 'N', Flanks{Site: "CGT", Upstream: "NNA", Downstream: "AA", UpstreamPad: 2}},
+		{"minus clamped upstream", 13, 16, "-", 0, Flanks{Site: "AAA", Upstream: "", Downstream: "CC", UpstreamPad: 3}},
+		{"minus padded upstream", 13, 16, "-", '-', Flanks{Site: "AAA", Upstream: "---", Downstream: "CC", UpstreamPad: 3}},
+		{"minus padded downstream", 0, 2, "-", 'N', Flanks{Site: "GT", Upstream: "TAC", Downstream: "NN", DownstreamPad: 2}},
+		{"both clamped", 0, 16, "+", 'N', Flanks{Site: "ACGTAAACCCGGGTTT", Upstream: "NNN", Downstream: "NN", UpstreamPad: 3, DownstreamPad: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Region(target, "t", tt.start, tt.end, tt.strand, Options{Upstream: 3, Downstream: 2, Pad: tt.pad})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.Truncated() != (tt.want.UpstreamPad+tt.want.DownstreamPad > 0) {
+				t.Errorf("Truncated() = %v", got.Truncated())
+			}
+		})
+	}
+
+	if _, err := Region(target, "t", 14, 17, "+", Options{}); !errors.Is(err, fasta.ErrRange) {
+		t.Errorf("site past the end gives %v", err)
+	}
+	if _, err := Region(target, "u", 0, 1, "+", Options{}); !errors.Is(err, fasta.ErrNotFound) {
+		t.Errorf("missing target gives %v", err)
+	}
+}
+
+func TestSites(t *testing.T) {
+	sites := []predict.TargetSite{
+		{TargetID: "t", Start: 6, End: 10, Strand: "+"},
+		{TargetID: "t", Start: 1, End: 4, Strand: "+"},
+		{TargetID: "u", Start: 0, End: 4, Strand: "+"},
+		{TargetID: "t", Start: 14, End: 17, Strand: "-"},
+		{TargetID: "t", Start: 13, End: 16, Strand: "-", Features: map[string]float64{"mfe": -20}},
+	}
+	tests := []struct {
+		name string
+		drop bool
+		want Summary
+		ids  []int
+	}{
+		{"keep truncated", false, Summary{Sites: 5, Extracted: 3, MissingTarget: 1, OutOfRange: 1, Truncated: 2}, []int{0, 1, 4}},
+		{"drop truncated", true, Summary{Sites: 5, Extracted: 1, MissingTarget: 1, OutOfRange: 1, Truncated: 2, Dropped: 2}, []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, sum, err := Sites(target, sites, Options{Upstream: 3, Downstream: 2, DropTruncated: tt.drop})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sum != tt.want {
+				t.Errorf("got summary %+v, want %+v", sum, tt.want)
+			}
+			if len(got) != len(tt.ids) {
+				t.Fatalf("got %d sites, want %d", len(got), len(tt.ids))
+			}
+			for i, s := range got {
+				in := sites[tt.ids[i]]
+				if s.Start != in.Start || s.Strand != in.Strand {
+					t.Errorf("site %d is %d%s, want %d%s", i, s.Start, s.Strand, in.Start, in.Strand)
+				}
+				if s.Features["upstream_pad"] != float64(s.UpstreamPad) || s.Features["downstream_pad"] != float64(s.DownstreamPad) {
+					t.Errorf("site %d: features %v", i, s.Features)
+				}
+			}
+		})
+	}
+	// The input features are copied, not written through.
+	if _, ok := sites[4].Features["upstream_pad"]; ok {
+		t.Error("Sites changed the features of its input")
+	}
+}
+
+func TestCenter(t *testing.T) {
+	tests := []struct {
+		name     string
+		strand   string
+		cleavage int
+		want     int
+	}{
+		{"plus", "+", 7, 4},
+		{"plus at the end", "+", 10, 7},
+		// On the minus strand the site reads from base 9 down, so a cut
+		// before base 7 falls before the site's last base.
+		{"minus", "-", 7, 6},
+		{"minus at the end", "-", 10, 3},
+		{"no cleavage", "-", -1, 5},
+		{"cleavage outside the site", "+", 12, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := predict.TargetSite{Start: 6, End: 10, Strand: tt.strand, Cleavage: tt.cleavage, Upstream: "CCC", Sequence: "GGGT"}
+			if got := center(s); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// The window of a minus strand site is centred on the base after the
+	// cut, the T complementing the A the plus strand is cut after.
+	sites := []predict.TargetSite{{TargetID: "t", Start: 6, End: 10, Strand: "-", Cleavage: 7}}
+	got, _, err := Sites(target, sites, Options{Upstream: 3, Downstream: 2, Window: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := got[0].Window; w == nil || w.Sequence != "GGTT" || w.Offset != 4 {
+		t.Errorf("got window %+v", w)
+	}
+}
@@ -0,0 +1,120 @@
+// Package fasta reads FASTA formatted target and genome files.
+package fasta
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Record is a single FASTA entry. ID is the header up to the first
+// whitespace and Desc holds whatever followed it.
+type Record struct {
+	ID   string
+	Desc string
+	Seq  string
+}
+
+// Reader streams records from a FASTA file, joining wrapped sequence
+// lines into a single sequence per record.
+type Reader struct {
+	r      *bufio.Reader
+	header []byte
+	line   int
+	// KeepCase preserves lowercase (soft-masked) bases instead of
+	// folding the sequence to uppercase.
+	KeepCase bool
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 1<<16)}
+}
+
+// Read returns the next record, or io.EOF once the input is exhausted.
+func (r *Reader) Read() (Record, error) {
+	var seq bytes.Buffer
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			if r.header == nil {
+				return Record{}, io.EOF
+			}
+			rec := r.record(seq.Bytes())
+			r.header = nil
+			return rec, nil
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		if len(line) == 0 || line[0] == ';' {
+			continue
+		}
+		if line[0] == '>' {
+			if r.header == nil {
+				r.header = append([]byte(nil), line[1:]...)
+				continue
+			}
+			rec := r.record(seq.Bytes())
+			r.header = append(r.header[:0], line[1:]...)
+			return rec, nil
+		}
+		if r.header == nil {
+			return Record{}, fmt.Errorf("fasta: line %d: sequence data before first header", r.line)
+		}
+		seq.Write(line)
+	}
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	return bytes.TrimSpace(line), nil
+}
+
+func (r *Reader) record(seq []byte) Record {
+	id, desc := strings.TrimSpace(string(r.header)), ""
+	if i := strings.IndexAny(id, " \t"); i >= 0 {
+		id, desc = id[:i], id[i+1:]
+	}
+	s := string(seq)
+	if !r.KeepCase {
+		s = strings.ToUpper(s)
+	}
+	return Record{
+		ID:   id,
+		Desc: strings.TrimSpace(desc),
+		Seq:  s,
+	}
+}
+
+// ReadFile reads every record of the FASTA file at path.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []Record{}
+	r := NewReader(f)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,113 @@
+package fasta
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wrap splits seq into lines of width bases.
+func wrap(seq string, width int) string {
+	var b strings.Builder
+	for i := 0; i < len(seq); i += width {
+		b.WriteString(seq[i:min(i+width, len(seq))])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func readAll(t *testing.T, r *Reader) ([]Record, error) {
+	t.Helper()
+	var records []Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestRead(t *testing.T) {
+	long := strings.Repeat("ACGTTGCAAG", 25)
+	tests := []struct {
+		name     string
+		in       string
+		keepCase bool
+		want     []Record
+	}{
+		{
+			"wrapped at 60",
+			">AT1G01010.1\n" + wrap(long, 60) + ">AT1G01020.1\n" + wrap(long[:61], 60),
+			false,
+			[]Record{{ID: "AT1G01010.1", Seq: long}, {ID: "AT1G01020.1", Seq: long[:61]}},
+		},
+		{
+			"wrapped at 80",
+			">chr1\n" + wrap(long, 80),
+			false,
+			[]Record{{ID: "chr1", Seq: long}},
+		},
+		{
+			"crlf",
+			strings.ReplaceAll(">t1 first target\n"+wrap(long, 60), "\n", "\r\n"),
+			false,
+			[]Record{{ID: "t1", Desc: "first target", Seq: long}},
+		},
+		{
+			"comments and blank lines",
+			"; made by hand\n\n>t1\nACGT\n; inside\n\nTTGA\n\n>t2\n\nCC\n",
+			false,
+			[]Record{{ID: "t1", Seq: "ACGTTTGA"}, {ID: "t2", Seq: "CC"}},
+		},
+		{
+			"description",
+			">AT2G33770.1 | Symbols: UBC24, PHO2 | phosphate 2\nACGT\n>t2\ttab separated  \nA\n",
+			false,
+			[]Record{{ID: "AT2G33770.1", Desc: "| Symbols: UBC24, PHO2 | phosphate 2", Seq: "ACGT"}, {ID: "t2", Desc: "tab separated", Seq: "A"}},
+		},
+		{
+			"lowercase folded",
+			">t1\nacgtNNnnACGT\n",
+			false,
+			[]Record{{ID: "t1", Seq: "ACGTNNNNACGT"}},
+		},
+		{
+			"keep case",
+			">t1\nacgtNNnnACGT\n",
+			true,
+			[]Record{{ID: "t1", Seq: "acgtNNnnACGT"}},
+		},
+		{
+			"empty record and no final newline",
+			">empty\n>t1\nAC\nGT",
+			false,
+			[]Record{{ID: "empty"}, {ID: "t1", Seq: "ACGT"}},
+		},
+		{"empty input", "", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.in))
+			r.KeepCase = tt.keepCase
+			got, err := readAll(t, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := readAll(t, NewReader(strings.NewReader("; header comes later\n\nACGT\n>t1\nACGT\n")))
+	if err == nil || !strings.Contains(err.Error(), "line 3: sequence data before first header") {
+		t.Errorf("got %v", err)
+	}
+}
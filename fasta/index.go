@@ -0,0 +1,249 @@
+package fasta
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when a sequence ID is not present.
+	ErrNotFound = errors.New("fasta: sequence not found")
+	// ErrRange is returned when a region lies outside its sequence.
+	ErrRange = errors.New("fasta: region out of range")
+)
+
+// Source gives access to regions of target sequences by ID. Coordinates
+// are 0-based and half-open.
+type Source interface {
+	Length(id string) (int, bool)
+	Fetch(id string, start, end int) (string, error)
+}
+
+// IndexEntry is one line of a samtools faidx index.
+type IndexEntry struct {
+	Name      string
+	Length    int64
+	Offset    int64
+	LineBases int64
+	LineWidth int64
+}
+
+// IndexPath returns the conventional index location for a FASTA file.
+func IndexPath(path string) string {
+	return path + ".fai"
+}
+
+// BuildIndex scans a FASTA stream and returns its faidx entries. Every
+// sequence line of a record except the last must have the same length.
+func BuildIndex(r io.Reader) ([]IndexEntry, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	entries := []IndexEntry{}
+	var (
+		cur      *IndexEntry
+		offset   int64
+		lineNo   int
+		shortRun bool
+	)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		lineNo++
+		width := int64(len(line))
+		bases := int64(len(bytes.TrimRight(line, "\r\n")))
+		switch {
+		case len(line) > 0 && line[0] == '>':
+			name := strings.Fields(string(line[1:]))
+			if len(name) == 0 {
+				return nil, fmt.Errorf("fasta: line %d: empty header", lineNo)
+			}
+			entries = append(entries, IndexEntry{
+				Name:   name[0],
+				Offset: offset + width,
+			})
+			cur = &entries[len(entries)-1]
+			shortRun = false
+		case cur == nil:
+			if bases > 0 {
+				return nil, fmt.Errorf("fasta: line %d: sequence data before first header", lineNo)
+			}
+		case bases == 0:
+			shortRun = cur.Length > 0
+		default:
+			if shortRun || (cur.LineBases > 0 && bases > cur.LineBases) {
+				return nil, fmt.Errorf("fasta: line %d: %s has uneven line lengths", lineNo, cur.Name)
+			}
+			if cur.LineBases == 0 {
+				cur.LineBases, cur.LineWidth = bases, width
+			} else if bases < cur.LineBases {
+				shortRun = true
+			}
+			cur.Length += bases
+		}
+		offset += width
+		if err == io.EOF {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// WriteIndex writes entries in the tab-delimited .fai layout.
+func WriteIndex(w io.Writer, entries []IndexEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\n", e.Name, e.Length, e.Offset, e.LineBases, e.LineWidth)
+	}
+	return bw.Flush()
+}
+
+// ReadIndex parses a .fai index.
+func ReadIndex(r io.Reader) ([]IndexEntry, error) {
+	entries := []IndexEntry{}
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) < 5 {
+			return nil, fmt.Errorf("fasta index: line %d: expected 5 columns, got %d", lineNo, len(cols))
+		}
+		var nums [4]int64
+		for i := range nums {
+			n, err := strconv.ParseInt(cols[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("fasta index: line %d: %w", lineNo, err)
+			}
+			nums[i] = n
+		}
+		entries = append(entries, IndexEntry{
+			Name:      cols[0],
+			Length:    nums[0],
+			Offset:    nums[1],
+			LineBases: nums[2],
+			LineWidth: nums[3],
+		})
+	}
+	return entries, sc.Err()
+}
+
+// IndexFile builds the index for the FASTA file at path and writes it
+// next to it.
+func IndexFile(path string) ([]IndexEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := BuildIndex(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	out, err := os.Create(IndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteIndex(out, entries); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return entries, out.Close()
+}
+
+// IndexedFile is a Source that reads regions straight from disk using a
+// .fai index, so only the requested bytes are ever loaded.
+type IndexedFile struct {
+	f       *os.File
+	entries map[string]IndexEntry
+	dups    []string
+	// KeepCase preserves soft-masked lowercase bases.
+	KeepCase bool
+}
+
+// OpenIndexed opens the FASTA file at path together with its .fai index.
+func OpenIndexed(path string) (*IndexedFile, error) {
+	idx, err := os.Open(IndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ReadIndex(idx)
+	idx.Close()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	x := &IndexedFile{f: f, entries: make(map[string]IndexEntry, len(entries))}
+	for _, e := range entries {
+		if _, dup := x.entries[e.Name]; dup {
+			x.dups = append(x.dups, e.Name)
+			continue
+		}
+		x.entries[e.Name] = e
+	}
+	return x, nil
+}
+
+// Duplicates lists the IDs indexed more than once; only the first
+// entry of each is used.
+func (x *IndexedFile) Duplicates() []string {
+	return x.dups
+}
+
+// Length returns the length of the sequence id.
+func (x *IndexedFile) Length(id string) (int, bool) {
+	e, ok := x.entries[id]
+	return int(e.Length), ok
+}
+
+// Fetch reads the bases in [start, end) of the sequence id.
+func (x *IndexedFile) Fetch(id string, start, end int) (string, error) {
+	e, ok := x.entries[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if start < 0 || int64(end) > e.Length || start > end {
+		return "", fmt.Errorf("%w: %s:%d-%d", ErrRange, id, start, end)
+	}
+	if start == end {
+		return "", nil
+	}
+	from := e.Offset + int64(start)/e.LineBases*e.LineWidth + int64(start)%e.LineBases
+	last := int64(end - 1)
+	to := e.Offset + last/e.LineBases*e.LineWidth + last%e.LineBases + 1
+	buf := make([]byte, to-from)
+	if _, err := x.f.ReadAt(buf, from); err != nil {
+		return "", fmt.Errorf("%s: %w", x.f.Name(), err)
+	}
+	seq := make([]byte, 0, end-start)
+	for _, b := range buf {
+		if b != '\n' && b != '\r' {
+			seq = append(seq, b)
+		}
+	}
+	if !x.KeepCase {
+		seq = bytes.ToUpper(seq)
+	}
+	return string(seq), nil
+}
+
+// Close releases the underlying file.
+func (x *IndexedFile) Close() error {
+	return x.f.Close()
+}
@@ -0,0 +1,139 @@
+package fasta
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// randomSeq returns n bases with some soft-masked stretches.
+func randomSeq(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = "ACGTacgtN"[rng.Intn(9)]
+	}
+	return string(b)
+}
+
+func TestIndex(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	seq1, seq2 := randomSeq(rng, 150), randomSeq(rng, 25)
+	lf := ">seq1 first target\n" + wrap(seq1, 60) + ">seq2\n" + wrap(seq2, 10)
+	tests := []struct {
+		name string
+		in   string
+		// fai is what samtools faidx writes for the same file.
+		fai string
+	}{
+		{"lf", lf, "seq1\t150\t19\t60\t61\nseq2\t25\t178\t10\t11\n"},
+		{"crlf", strings.ReplaceAll(lf, "\n", "\r\n"), "seq1\t150\t20\t60\t62\nseq2\t25\t183\t10\t12\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.fasta")
+			if err := os.WriteFile(path, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := IndexFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := WriteIndex(&buf, entries); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.fai {
+				t.Errorf("got index\n%s\nwant\n%s", buf.String(), tt.fai)
+			}
+			written, err := os.ReadFile(IndexPath(path))
+			if err != nil || string(written) != tt.fai {
+				t.Errorf("index file holds %q, %v", written, err)
+			}
+
+			indexed, err := OpenIndexed(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer indexed.Close()
+			store, err := LoadStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []string{"seq1", "seq2"} {
+				n, _ := store.Length(id)
+				if m, ok := indexed.Length(id); !ok || m != n {
+					t.Fatalf("%s: indexed length %d, stored %d", id, m, n)
+				}
+				// Every range, so each crosses line ends at every offset.
+				for start := 0; start <= n; start++ {
+					for end := start; end <= n; end++ {
+						want, _ := store.Fetch(id, start, end)
+						got, err := indexed.Fetch(id, start, end)
+						if err != nil || got != want {
+							t.Fatalf("%s:%d-%d: got %q, %v, want %q", id, start, end, got, err, want)
+						}
+					}
+				}
+			}
+			if _, err := indexed.Fetch("seq2", 20, 26); err == nil {
+				t.Error("fetch past the end succeeded")
+			}
+			if _, err := indexed.Fetch("seq3", 0, 1); err == nil {
+				t.Error("fetch of a missing sequence succeeded")
+			}
+		})
+	}
+}
+
+func TestIndexKeepCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "masked.fasta")
+	if err := os.WriteFile(path, []byte(">t\nACgt\nnnAC\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := IndexFile(path); err != nil {
+		t.Fatal(err)
+	}
+	indexed, err := OpenIndexed(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indexed.Close()
+	for keep, want := range map[bool]string{false: "GTNN", true: "gtnn"} {
+		indexed.KeepCase = keep
+		if got, _ := indexed.Fetch("t", 2, 6); got != want {
+			t.Errorf("KeepCase %v: got %q, want %q", keep, got, want)
+		}
+	}
+}
+
+func TestBuildIndexErrors(t *testing.T) {
+	tests := []struct {
+		name, in, err string
+	}{
+		{"short line inside record", ">a\nACGT\nAC\nACGT\n", "line 4: a has uneven line lengths"},
+		{"long line after first", ">a\nAC\nACGT\n", "line 3: a has uneven line lengths"},
+		{"blank line inside record", ">a\nACGT\n\nACGT\n", "line 4: a has uneven line lengths"},
+		{"sequence before header", "ACGT\n>a\nACGT\n", "line 1: sequence data before first header"},
+		{"empty header", ">\nACGT\n", "line 1: empty header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildIndex(strings.NewReader(tt.in))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	// A short last line and blank lines between records are fine.
+	entries, err := BuildIndex(strings.NewReader(">a\nACGT\nAC\n\n>b\nA\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Length != 6 || entries[1].Offset != 15 {
+		t.Errorf("got %+v", entries)
+	}
+}
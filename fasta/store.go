@@ -0,0 +1,74 @@
+package fasta
+
+import "fmt"
+
+// Store is an in-memory Source keyed by sequence ID, so each prediction
+// is joined to its target with a single map lookup.
+type Store struct {
+	records []Record
+	byID    map[string]int
+	dups    []string
+}
+
+// NewStore indexes records by ID. When an ID occurs more than once the
+// first record is kept and the ID is reported by Duplicates.
+func NewStore(records []Record) *Store {
+	s := &Store{
+		records: records,
+		byID:    make(map[string]int, len(records)),
+	}
+	for i := range records {
+		if _, dup := s.byID[records[i].ID]; dup {
+			s.dups = append(s.dups, records[i].ID)
+			continue
+		}
+		s.byID[records[i].ID] = i
+	}
+	return s
+}
+
+// LoadStore reads the FASTA file at path into a Store.
+func LoadStore(path string) (*Store, error) {
+	records, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(records), nil
+}
+
+// Get returns the record stored under id.
+func (s *Store) Get(id string) (Record, bool) {
+	i, ok := s.byID[id]
+	if !ok {
+		return Record{}, false
+	}
+	return s.records[i], true
+}
+
+// Len returns the number of distinct IDs in the store.
+func (s *Store) Len() int {
+	return len(s.byID)
+}
+
+// Duplicates lists every repeated occurrence of an ID in input order.
+func (s *Store) Duplicates() []string {
+	return s.dups
+}
+
+// Length returns the length of the sequence id.
+func (s *Store) Length(id string) (int, bool) {
+	rec, ok := s.Get(id)
+	return len(rec.Seq), ok
+}
+
+// Fetch returns the bases in [start, end) of the sequence id.
+func (s *Store) Fetch(id string, start, end int) (string, error) {
+	rec, ok := s.Get(id)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if start < 0 || end > len(rec.Seq) || start > end {
+		return "", fmt.Errorf("%w: %s:%d-%d", ErrRange, id, start, end)
+	}
+	return rec.Seq[start:end], nil
+}
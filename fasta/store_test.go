@@ -0,0 +1,140 @@
+package fasta
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// syntheticRecords returns n targets of 300 bases with unique IDs.
+func syntheticRecords(n int) []Record {
+	rng := rand.New(rand.NewSource(1))
+	bases := []byte("ACGT")
+	records := make([]Record, n)
+	for i := range records {
+		seq := make([]byte, 300)
+		for j := range seq {
+			seq[j] = bases[rng.Intn(len(bases))]
+		}
+		records[i] = Record{ID: fmt.Sprintf("AT%dG%05d.1", i%5+1, i), Seq: string(seq)}
+	}
+	return records
+}
+
+// syntheticPredictions picks n target IDs to join against.
+func syntheticPredictions(records []Record, n int) []string {
+	rng := rand.New(rand.NewSource(2))
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = records[rng.Intn(len(records))].ID
+	}
+	return ids
+}
+
+// linearFetch is the nested prediction x FASTA scan the store replaces.
+func linearFetch(records []Record, id string, start, end int) (string, bool) {
+	for j := range records {
+		if records[j].ID == id {
+			return records[j].Seq[start:end], true
+		}
+	}
+	return "", false
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore([]Record{
+		{ID: "a", Seq: "AAAA"},
+		{ID: "b", Seq: "CCCC"},
+		{ID: "a", Seq: "GGGG"},
+		{ID: "c", Seq: "TT"},
+		{ID: "b", Seq: "NN"},
+		{ID: "a", Seq: "ACGT"},
+	})
+	if store.Len() != 3 {
+		t.Errorf("got %d IDs, want 3", store.Len())
+	}
+	// Repeats are reported in input order and the first record is kept.
+	if want := []string{"a", "b", "a"}; !reflect.DeepEqual(store.Duplicates(), want) {
+		t.Errorf("got duplicates %v, want %v", store.Duplicates(), want)
+	}
+	for id, want := range map[string]string{"a": "AAAA", "b": "CCCC", "c": "TT"} {
+		if rec, ok := store.Get(id); !ok || rec.Seq != want {
+			t.Errorf("%s: got %q, want %q", id, rec.Seq, want)
+		}
+	}
+
+	tests := []struct {
+		id         string
+		start, end int
+		want       string
+		err        error
+	}{
+		{"a", 1, 3, "AA", nil},
+		{"c", 0, 2, "TT", nil},
+		{"c", 2, 2, "", nil},
+		{"c", 1, 3, "", ErrRange},
+		{"c", -1, 1, "", ErrRange},
+		{"c", 2, 1, "", ErrRange},
+		{"d", 0, 1, "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := store.Fetch(tt.id, tt.start, tt.end)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Fetch(%s, %d, %d) = %q, %v, want %q, %v", tt.id, tt.start, tt.end, got, err, tt.want, tt.err)
+		}
+	}
+	if n, ok := store.Length("d"); ok || n != 0 {
+		t.Errorf("missing ID has length %d, %v", n, ok)
+	}
+	if store := NewStore(syntheticRecords(100)); len(store.Duplicates()) != 0 || store.Len() != 100 {
+		t.Errorf("unique IDs reported %v", store.Duplicates())
+	}
+}
+
+var sizes = []int{1000, 10000, 40000}
+
+func BenchmarkLinearJoin(b *testing.B) {
+	for _, n := range sizes {
+		records := syntheticRecords(n)
+		preds := syntheticPredictions(records, 1000)
+		b.Run(fmt.Sprintf("targets=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, id := range preds {
+					if _, ok := linearFetch(records, id, 100, 121); !ok {
+						b.Fatal(id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStoreJoin(b *testing.B) {
+	for _, n := range sizes {
+		records := syntheticRecords(n)
+		preds := syntheticPredictions(records, 1000)
+		store := NewStore(records)
+		b.Run(fmt.Sprintf("targets=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, id := range preds {
+					if _, err := store.Fetch(id, 100, 121); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewStore(b *testing.B) {
+	for _, n := range sizes {
+		records := syntheticRecords(n)
+		b.Run(fmt.Sprintf("targets=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewStore(records)
+			}
+		})
+	}
+}
@@ -13,11 +13,21 @@ and prepares them for the deep learning.
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/go-microRNAs/encode"
+	"github.com/go-microRNAs/extract"
+	"github.com/go-microRNAs/fasta"
+	"github.com/go-microRNAs/output"
+	"github.com/go-microRNAs/predict"
+	"github.com/go-microRNAs/sitedb"
+	"github.com/go-microRNAs/tokenize"
 	"github.com/spf13/cobra"
 )
 
@@ -40,8 +50,35 @@ var (
 	upstream      int
 	downstream    int
 	psRobotFile   string
+	outFormat     string
+	outPath       string
+	padChar       string
+	dropTruncated bool
+	coordsFlag    string
+	encodingFlag  string
+	siteLabel     int
+	shards        int
+	dbPath        string
+	queryMiRNA    string
+	queryTarget   string
+	queryTool     string
+	queryPairs    bool
+	tokInput      string
+	tokOutput     string
+	vocabPath     string
+	kmerSize      int
+	kmerStride    int
+	tokenizerPath string
+	bpeVocabSize  int
+	bpeMinFreq    int
+	decodeIDs     string
+	windowLen     int
 )
 
+// defaultDB is where --format sqlite writes and query reads unless told
+// otherwise, so runs of several tools share one database.
+const defaultDB = "sites.sqlite"
+
 var rootCmd = &cobra.Command{
 	Use:  "analyzePred",
 	Long: "This analyzes the microRNA prediction and makes them ready for the deep learning approaches",
@@ -83,6 +120,30 @@ var psRobotCmd = &cobra.Command{
 	Run:  psRobotFunc,
 }
 
+var indexCmd = &cobra.Command{
+	Use:  "index",
+	Long: "Builds a samtools compatible .fai index so the target fasta is read by region",
+	Run:  indexFunc,
+}
+
+var queryCmd = &cobra.Command{
+	Use:  "query",
+	Long: "Looks up sites written with --format sqlite by miRNA, target and tool",
+	Run:  queryFunc,
+}
+
+var tokenizeCmd = &cobra.Command{
+	Use:  "tokenize",
+	Long: "Splits the upstream, site and downstream sequences of fasta, tsv or jsonl output into k-mer tokens",
+	Run:  tokenizeFunc,
+}
+
+var bpeCmd = &cobra.Command{
+	Use:  "bpe",
+	Long: "Trains a byte-pair-encoding tokenizer on the target fasta, or loads one, and encodes or decodes sites with it",
+	Run:  bpeFunc,
+}
+
 func init() {
 	psRNACmd.Flags().
 		StringVarP(&psRNAPred, "psRNAPred", "p", "psRNA microRNA predictions", "psRNA predictions")
@@ -95,7 +156,7 @@ func init() {
 	psRNACmd.Flags().
 		IntVarP(&downstream, "downstream", "D", 10, "downstream of the miRNA predictions")
 	tapirCmd.Flags().
-		StringVarP(&psRNAPred, "tapir", "p", "psRNA microRNA predictions", "psRNA predictions")
+		StringVarP(&tapirPred, "tapir", "p", "tapir microRNA predictions", "tapir predictions")
 	tapirCmd.Flags().
 		StringVarP(&fastPred, "fastapred", "f", "fasta file for the predictions", "fasta predict")
 	tapirCmd.Flags().
@@ -134,6 +195,62 @@ func init() {
 		IntVarP(&upstream, "upstream", "U", 10, "upstream of the miRNA predictions")
 	psRobotCmd.Flags().
 		IntVarP(&downstream, "downstream", "D", 10, "downstream of the miRNA predictions")
+	for _, c := range []*cobra.Command{psRNACmd, tapirCmd, psRNAMapCmd, tarHunterCmd, tarFinderCmd, psRobotCmd} {
+		c.Flags().
+			StringVar(&outFormat, "format", "fasta", "output format: "+strings.Join(output.Formats(), ", "))
+		c.Flags().
+			StringVarP(&outPath, "output", "o", "", "output file (default named after the tool with the format extension, or sites.sqlite)")
+		c.Flags().
+			StringVar(&padChar, "pad", "", "character padding flanks that run past the target ends, e.g. N (default truncate)")
+		c.Flags().
+			BoolVar(&dropTruncated, "drop-truncated", false, "skip sites whose flanks run past the target ends")
+		c.Flags().
+			StringVar(&coordsFlag, "coords", "1", "coordinates written for sites: 0 (0-based half-open) or 1 (1-based inclusive)")
+		c.Flags().
+			StringVar(&encodingFlag, "encoding", "onehot", "sequence encoding of the tensor formats: onehot or integer")
+		c.Flags().
+			IntVar(&siteLabel, "label", 1, "label stored for every site by the tensor formats")
+		c.Flags().
+			IntVar(&shards, "shards", 1, "number of files tfrecord output is split over")
+		c.Flags().
+			IntVar(&windowLen, "window", 0, "cut sites to this many bases centred on the cleavage position or the site middle, with attention mask and segment IDs (0 keeps whole sites)")
+	}
+	indexCmd.Flags().
+		StringVarP(&fastPred, "fastapred", "f", "fasta file for the predictions", "fasta file to index")
+	queryCmd.Flags().
+		StringVarP(&dbPath, "db", "d", defaultDB, "database written with --format sqlite")
+	queryCmd.Flags().
+		StringVarP(&queryMiRNA, "mirna", "m", "", "miRNA ID, * and ? match as globs")
+	queryCmd.Flags().
+		StringVarP(&queryTarget, "target", "t", "", "target ID, * and ? match as globs")
+	queryCmd.Flags().
+		StringVar(&queryTool, "tool", "", "tool the sites were predicted by")
+	queryCmd.Flags().
+		BoolVar(&queryPairs, "pairs", false, "list miRNA and target pairs with the tools predicting them instead of sites")
+	tokenizeCmd.Flags().
+		StringVarP(&tokInput, "input", "i", "", "sites written with --format fasta, tsv or jsonl")
+	tokenizeCmd.Flags().
+		StringVarP(&tokOutput, "output", "o", "", "token table (default the input name with .tokens.tsv)")
+	tokenizeCmd.Flags().
+		StringVar(&vocabPath, "vocab", "vocab.txt", "file the vocabulary is written to")
+	tokenizeCmd.Flags().
+		IntVarP(&kmerSize, "kmer", "k", 6, "k-mer size")
+	tokenizeCmd.Flags().
+		IntVar(&kmerStride, "stride", 1, "bases between the starts of consecutive k-mers")
+	bpeCmd.Flags().
+		StringVarP(&fastPred, "fastapred", "f", "fasta file for the predictions", "target fasta to train on; without it --tokenizer is loaded")
+	bpeCmd.Flags().
+		StringVarP(&tokenizerPath, "tokenizer", "t", "tokenizer.json", "HuggingFace tokenizers JSON file written by training or read")
+	bpeCmd.Flags().
+		IntVar(&bpeVocabSize, "vocab-size", tokenize.DefaultTrainOptions.VocabSize, "vocabulary size to train to, special tokens included")
+	bpeCmd.Flags().
+		IntVar(&bpeMinFreq, "min-frequency", tokenize.DefaultTrainOptions.MinFrequency, "fewest occurrences of a pair to merge")
+	bpeCmd.Flags().
+		StringVarP(&tokInput, "input", "i", "", "sites written with --format fasta, tsv or jsonl to encode")
+	bpeCmd.Flags().
+		StringVarP(&tokOutput, "output", "o", "", "token table (default the input name with .tokens.tsv)")
+	bpeCmd.Flags().
+		StringVar(&decodeIDs, "decode", "", "space separated token IDs to decode into upstream, site and downstream")
 
 	rootCmd.AddCommand(psRNACmd)
 	rootCmd.AddCommand(tapirCmd)
@@ -141,658 +258,356 @@ func init() {
 	rootCmd.AddCommand(tarHunterCmd)
 	rootCmd.AddCommand(tarFinderCmd)
 	rootCmd.AddCommand(psRobotCmd)
+	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(tokenizeCmd)
+	rootCmd.AddCommand(bpeCmd)
 }
 
 func psRNAFunc(cmd *cobra.Command, args []string) {
-	type psRNAStruct struct {
-		miRNA         string
-		target        string
-		evalue        float64
-		targetStart   int
-		targetEnd     int
-		miRNAAligned  string
-		targetAligned string
-	}
-
-	type psRNAStructFiltered struct {
-		miRNA         string
-		target        string
-		evalue        float64
-		targetStart   int
-		targetEnd     int
-		miRNAAligned  string
-		targetAligned string
-	}
-
-	type fastPredID struct {
-		id string
-	}
-
-	type fastPredSeq struct {
-		seq string
-	}
-
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+	storemiRNA, err := predict.ReadPsRNATargetFile(psRNAPred)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
-		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
+
+	sites := []predict.TargetSite{}
+	for i := range storemiRNA {
+		if storemiRNA[i].Expectation <= evalue {
+			sites = append(sites, storemiRNA[i].Site())
 		}
 	}
+	extractAndWrite("psRNANeural", sites)
+}
 
-	storemiRNA := []psRNAStruct{}
-	filteredmiRNA := []psRNAStructFiltered{}
-
-	fOpen, err := os.Open(psRNAPred)
+func tapirFunc(cmd *cobra.Command, args []string) {
+	hits, err := predict.ReadTapirFile(tapirPred)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fRead := bufio.NewScanner(fOpen)
-	for fRead.Scan() {
-		line := fRead.Text()
-		if strings.HasPrefix(string(line), "#") {
-			continue
-		}
-		if !strings.HasPrefix(string(line), "#") {
-			evalueMid, _ := strconv.ParseFloat(strings.Split(string(line), " ")[2], 64)
-			targetStartMid, _ := strconv.Atoi(strings.Split(string(line), " ")[6])
-			targetEndMid, _ := strconv.Atoi(strings.Split(string(line), " ")[7])
-			storemiRNA = append(storemiRNA, psRNAStruct{
-				miRNA:         strings.Split(string(line), " ")[0],
-				target:        strings.Split(string(line), " ")[1],
-				evalue:        evalueMid,
-				targetStart:   targetStartMid,
-				targetEnd:     targetEndMid,
-				miRNAAligned:  strings.Split(string(line), " ")[8],
-				targetAligned: strings.Split(string(line), " ")[9],
-			})
-		}
-	}
-
-	for i := range storemiRNA {
-		if storemiRNA[i].evalue <= evalue {
-			filteredmiRNA = append(filteredmiRNA, psRNAStructFiltered{
-				miRNA:         storemiRNA[i].miRNA,
-				target:        storemiRNA[i].target,
-				evalue:        storemiRNA[i].evalue,
-				targetStart:   storemiRNA[i].targetStart,
-				targetEnd:     storemiRNA[i].targetEnd,
-				miRNAAligned:  storemiRNA[i].miRNAAligned,
-				targetAligned: storemiRNA[i].targetAligned,
-			})
-		}
-	}
 
-	type extractSeq struct {
-		target      string
-		targetSeq   string
-		targetcomp  string
-		targetStart int
-		targetEnd   int
-		upstream    string
-		downstream  string
-	}
-
-	targetExtract := []extractSeq{}
-
-	for i := range filteredmiRNA {
-		for j := range fastID {
-			if filteredmiRNA[i].target == fastID[j].id {
-				targetExtract = append(targetExtract, extractSeq{
-					target:      filteredmiRNA[i].target,
-					targetSeq:   fastSeq[j].seq[filteredmiRNA[i].targetStart:filteredmiRNA[i].targetEnd],
-					targetStart: filteredmiRNA[i].targetStart,
-					targetEnd:   filteredmiRNA[i].targetEnd,
-					targetcomp:  fastSeq[j].seq,
-					upstream:    fastSeq[j].seq[filteredmiRNA[i].targetStart-upstream : filteredmiRNA[i].targetEnd],
-					downstream:  fastSeq[j].seq[filteredmiRNA[i].targetEnd : filteredmiRNA[i].targetEnd+downstream],
-				})
-			}
-		}
+	sites := make([]predict.TargetSite, 0, len(hits))
+	for i := range hits {
+		sites = append(sites, hits[i].Site())
 	}
+	extractAndWrite("tapirneural", sites)
+}
 
-	psRNAneural, err := os.Create("psRNANeural.fasta")
+func psRNAMapFunc(cmd *cobra.Command, args []string) {
+	readMap, err := predict.ReadPsRNAMapFile(psRNAfile)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer psRNAneural.Close()
-	for i := range targetExtract {
-		psRNAneural.WriteString(
-			targetExtract[i].target + "\t" + ">" + targetExtract[i].targetSeq + "\t" + targetExtract[i].targetcomp + "\t" + targetExtract[i].upstream + "\t" + targetExtract[i].downstream,
-		)
+
+	sites := make([]predict.TargetSite, 0, len(readMap))
+	for i := range readMap {
+		sites = append(sites, readMap[i].Site())
 	}
+	extractAndWrite("psRNAMap", sites)
 }
 
-func tapirFunc(cmd *cobra.Command, args []string) {
-	target := []string{}
-	miRNA := []string{}
-	score := []int{}
-	mfe := []int{}
-	start := []int{}
-	end := []int{}
-
-	tapirOpen, err := os.Open(tapirPred)
+func tarFunc(cmd *cobra.Command, args []string) {
+	tarStore, err := predict.ReadTarHunterFile(tarHunter)
 	if err != nil {
 		log.Fatal(err)
 	}
-	tapirRead := bufio.NewScanner(tapirOpen)
-	for tapirRead.Scan() {
-		line := tapirRead.Text()
-		if strings.HasPrefix(string(line), "target") {
-			target = append(target, strings.Split(string(line), " ")[1])
-		}
-		if strings.HasPrefix(string(line), "miRNA") {
-			miRNA = append(miRNA, strings.Split(strings.Split(string(line), ":")[3], "=")[2])
-		}
-		if strings.HasPrefix(string(line), "score") {
-			scoreInter, _ := strconv.Atoi(strings.Split(string(line), " ")[1])
-			score = append(score, scoreInter)
-		}
-		if strings.HasPrefix(string(line), "mfe") {
-			mfeInter, _ := strconv.Atoi(strings.Split(string(line), " ")[1])
-			mfe = append(mfe, mfeInter)
-		}
-		if strings.HasPrefix(string(line), "start") {
-			startInter, _ := strconv.Atoi(strings.Split(string(line), " ")[1])
-			start = append(start, startInter)
-		}
-		if strings.HasPrefix(string(line), "target_5'") {
-			end = append(end, len(strings.Split(string(line), " ")[1]))
-		}
-	}
 
-	type tapirExtract struct {
-		tapid      string
-		tapseq     string
-		tapcomp    string
-		upstream   string
-		downstream string
+	sites := make([]predict.TargetSite, 0, len(tarStore))
+	for i := range tarStore {
+		sites = append(sites, tarStore[i].Site())
 	}
+	extractAndWrite("tarHunter", sites)
+}
 
-	type fastPredID struct {
-		id string
+func tarFinderFunc(cmd *cobra.Command, args []string) {
+	tarFinderAdd, err := predict.ReadTargetFinderFile(tarFinderFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	type fastPredSeq struct {
-		seq string
+	sites := make([]predict.TargetSite, 0, len(tarFinderAdd))
+	for i := range tarFinderAdd {
+		sites = append(sites, tarFinderAdd[i].Site())
 	}
+	extractAndWrite("tarFinder", sites)
+}
 
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+func psRobotFunc(cmd *cobra.Command, args []string) {
+	psRobotC, err := predict.ReadPsRobotFile(psRobotFile)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
-		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
-		}
-	}
 
-	tapSeq := []tapirExtract{}
-
-	for i := range target {
-		for j := range fastID {
-			if target[i] == fastID[j].id {
-				tapSeq = append(tapSeq, tapirExtract{
-					tapid:      target[i],
-					tapseq:     fastSeq[j].seq[start[i]:end[i]],
-					tapcomp:    fastSeq[j].seq,
-					upstream:   fastSeq[j].seq[start[i]-upstream : start[i]],
-					downstream: fastSeq[j].seq[end[i] : end[i]+downstream],
-				})
-			}
-		}
+	sites := make([]predict.TargetSite, 0, len(psRobotC))
+	for i := range psRobotC {
+		sites = append(sites, psRobotC[i].Site())
 	}
+	extractAndWrite("psRobot", sites)
+}
 
-	tapwrite, err := os.Create("tapirneural.fasta")
+// extractAndWrite cuts the sites and their flanks out of the target
+// fasta and writes them in the selected --format. Unless --output is
+// given the file is named after the tool with the format's extension,
+// except that sqlite output goes to the shared defaultDB.
+func extractAndWrite(base string, sites []predict.TargetSite) {
+	ext, err := output.Extension(outFormat)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer tapwrite.Close()
-	for i := range tapSeq {
-		tapwrite.WriteString(
-			tapSeq[i].tapid + "\t" + tapSeq[i].tapseq + "\t" + tapSeq[i].tapcomp + "\t" + tapSeq[i].upstream + "\t" + tapSeq[i].downstream,
-		)
-	}
-}
-
-func psRNAMapFunc(cmd *cobra.Command, args []string) {
-	type psRNAfunc struct {
-		id     string
-		ref    string
-		strand string
-		start  int
-		stop   int
-		read   string
+	coords, err := predict.ParseConvention(coordsFlag)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	readMap := []psRNAfunc{}
-	fOpen, err := os.Open(psRNAfile)
+	encoding, err := encode.ParseScheme(encodingFlag)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fRead := bufio.NewScanner(fOpen)
-	for fRead.Scan() {
-		line := fRead.Text()
-		startStore, _ := strconv.Atoi(strings.Split(string(line), " ")[3])
-		endStore, _ := strconv.Atoi(strings.Split(string(line), " ")[4])
-		readMap = append(readMap, psRNAfunc{
-			id:     strings.Split(string(line), " ")[0],
-			ref:    strings.Split(string(line), " ")[1],
-			strand: strings.Split(string(line), " ")[2],
-			start:  startStore,
-			stop:   endStore,
-			read:   strings.Split(string(line), " ")[5],
-		})
+	path := outPath
+	switch {
+	case path != "":
+	case outFormat == "sqlite":
+		path = defaultDB
+	default:
+		path = base + ext
 	}
 
-	type fastPredID struct {
-		id string
+	if upstream < 0 || downstream < 0 {
+		log.Fatalf("--upstream and --downstream cannot be negative, got %d and %d", upstream, downstream)
 	}
-
-	type fastPredSeq struct {
-		seq string
+	targets := openTargets(fastPred)
+	opt := extract.Options{
+		Upstream:      upstream,
+		Downstream:    downstream,
+		DropTruncated: dropTruncated,
+		Window:        windowLen,
 	}
+	if len(padChar) > 1 {
+		log.Fatalf("--pad takes a single character, got %q", padChar)
+	}
+	if padChar != "" {
+		opt.Pad = padChar[0]
+	}
+	extracted, summary, err := extract.Sites(targets, sites, opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s: extracted %d of %d sites; %d on targets missing from %s, %d outside their target, %d with truncated flanks (%d dropped)",
+		path, summary.Extracted, summary.Sites, summary.MissingTarget, fastPred,
+		summary.OutOfRange, summary.Truncated, summary.Dropped)
 
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+	w, err := output.Create(outFormat, path, output.Options{
+		Coords:   coords,
+		Encoding: encoding,
+		Label:    siteLabel,
+		Shards:   shards,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
-		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
+	for _, s := range extracted {
+		if err := w.Write(s); err != nil {
+			log.Fatal(err)
 		}
 	}
-
-	type readMapSeq struct {
-		read       string
-		start      int
-		stop       int
-		refseq     string
-		refcomp    string
-		upstream   string
-		downstream string
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	readMapSeqStore := []readMapSeq{}
-
-	for i := range readMap {
-		for j := range fastID {
-			if readMap[i].id == fastID[i].id {
-				readMapSeqStore = append(readMapSeqStore, readMapSeq{
-					read:       readMap[i].read,
-					start:      readMap[i].start,
-					stop:       readMap[i].stop,
-					refseq:     fastSeq[j].seq[readMap[i].start:readMap[i].stop],
-					refcomp:    fastSeq[j].seq,
-					upstream:   fastSeq[j].seq[readMap[i].start-upstream : readMap[i].start],
-					downstream: fastSeq[j].seq[readMap[i].stop : readMap[i].stop+downstream],
-				})
-			}
+// openTargets opens the target FASTA, reading regions through its .fai
+// index when one has been built and loading it into a Store otherwise.
+// Repeated target IDs are reported; the first sequence of each is used.
+func openTargets(path string) fasta.Source {
+	if _, err := os.Stat(fasta.IndexPath(path)); err == nil {
+		indexed, err := fasta.OpenIndexed(path)
+		if err != nil {
+			log.Fatal(err)
 		}
+		reportDuplicates(path, indexed.Duplicates())
+		return indexed
 	}
-
-	readMapWrite, err := os.Create("psRNAMap.fasta")
+	store, err := fasta.LoadStore(path)
 	if err != nil {
 		log.Fatal(err)
 	}
-	for i := range readMapSeqStore {
-		readMapWrite.WriteString(
-			readMapSeqStore[i].read + "\t" + readMapSeqStore[i].refseq + "\t" + readMapSeqStore[i].refseq + "\t" + readMapSeqStore[i].refcomp + "\t" + readMapSeqStore[i].upstream + "\t" + readMapSeqStore[i].downstream,
-		)
-	}
+	reportDuplicates(path, store.Duplicates())
+	return store
 }
 
-func tarFunc(cmd *cobra.Command, args []string) {
-	type tarAnalyze struct {
-		targetID  string
-		targetSeq string
-		miRID     string
-		miRSeq    string
-		start     int
-		end       int
+func reportDuplicates(path string, dups []string) {
+	if len(dups) == 0 {
+		return
 	}
+	shown := dups
+	if len(shown) > 10 {
+		shown = shown[:10]
+	}
+	log.Printf("%s: %d duplicate target IDs, keeping the first sequence of each: %s",
+		path, len(dups), strings.Join(shown, ", "))
+}
 
-	tarStore := []tarAnalyze{}
-
-	fOpen, err := os.Open(tarHunter)
+func indexFunc(cmd *cobra.Command, args []string) {
+	entries, err := fasta.IndexFile(fastPred)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fRead := bufio.NewScanner(fOpen)
-	for fRead.Scan() {
-		line := fRead.Text()
-		startAppend, _ := strconv.Atoi(strings.Split(string(line), " ")[9])
-		endAppend, _ := strconv.Atoi(strings.Split(string(line), " ")[10])
-		tarStore = append(tarStore, tarAnalyze{
-			targetID:  strings.Split(string(line), " ")[0],
-			targetSeq: strings.Split(string(line), " ")[1],
-			miRID:     strings.Split(string(line), " ")[2],
-			miRSeq:    strings.Split(string(line), " ")[3],
-			start:     startAppend,
-			end:       endAppend,
-		})
-	}
+	log.Printf("indexed %d sequences into %s", len(entries), fasta.IndexPath(fastPred))
+}
 
-	type fastPredID struct {
-		id string
+func queryFunc(cmd *cobra.Command, args []string) {
+	if _, err := os.Stat(dbPath); err != nil {
+		log.Fatal(err)
 	}
-
-	type fastPredSeq struct {
-		seq string
+	filter := sitedb.Filter{MiRNA: queryMiRNA, Target: queryTarget, Tool: queryTool}
+	lookup := sitedb.Sites
+	if queryPairs {
+		lookup = sitedb.Pairs
 	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := lookup(dbPath, filter, w); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+func tokenizeFunc(cmd *cobra.Command, args []string) {
+	kmer, err := tokenize.NewKMer(kmerSize, kmerStride)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
+	writeTokens(kmer, vocabPath)
+}
+
+func bpeFunc(cmd *cobra.Command, args []string) {
+	var bpe *tokenize.BPE
+	if cmd.Flags().Changed("fastapred") {
+		bpe = trainBPE()
+	} else {
+		f, err := os.Open(tokenizerPath)
+		if err != nil {
+			log.Fatal(err)
 		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
+		bpe, err = tokenize.ReadJSON(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", tokenizerPath, err)
 		}
 	}
-
-	type tarSeqStore struct {
-		targetID         string
-		targetSeq        string
-		miRNAID          string
-		miRANSeq         string
-		targetSeqComp    string
-		targetUpstream   string
-		targetdownStream string
+	if tokInput != "" {
+		writeTokens(bpe, "")
 	}
-
-	tarStoreCapture := []tarSeqStore{}
-
-	for i := range tarStore {
-		for j := range fastID {
-			if tarStore[i].targetSeq == fastID[j].id {
-				tarStoreCapture = append(tarStoreCapture, tarSeqStore{
-					targetID:         tarStore[i].targetID,
-					targetSeq:        tarStore[i].targetSeq,
-					targetSeqComp:    fastSeq[j].seq,
-					targetUpstream:   fastSeq[j].seq[tarStore[i].start : tarStore[i].start-upstream],
-					targetdownStream: fastSeq[j].seq[tarStore[i].end : tarStore[i].end+downstream],
-				})
+	if decodeIDs != "" {
+		var ids []int
+		for _, field := range strings.Fields(decodeIDs) {
+			id, err := strconv.Atoi(field)
+			if err != nil {
+				log.Fatalf("--decode: %v", err)
 			}
+			ids = append(ids, id)
 		}
-	}
-
-	tarHunterWrite, err := os.Create("tarHunter.fasta")
-	if err != nil {
-		log.Fatal(err)
-	}
-	for i := range tarStoreCapture {
-		tarHunterWrite.WriteString(
-			tarStoreCapture[i].targetID + "\t" + tarStoreCapture[i].targetSeq + "\t" + tarStoreCapture[i].targetSeq + tarStoreCapture[i].targetSeqComp + "\t" + tarStoreCapture[i].targetUpstream + "\t" + tarStoreCapture[i].targetdownStream,
-		)
+		parts, err := tokenize.Decode(bpe.Vocab(), ids)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(strings.Join(parts, "\t"))
 	}
 }
 
-func tarFinderFunc(cmd *cobra.Command, args []string) {
-	type tarFinderCap struct {
-		miRNA    string
-		sequence string
-		start    int
-		end      int
-		mfe      float64
-	}
-
-	tarFinderAdd := []tarFinderCap{}
-
-	fOpen, err := os.Open(tarFinderFile)
+// trainBPE trains on every sequence of the target fasta and saves the
+// tokenizer to --tokenizer.
+func trainBPE() *tokenize.BPE {
+	opt := tokenize.DefaultTrainOptions
+	opt.VocabSize, opt.MinFrequency = bpeVocabSize, bpeMinFreq
+	trainer, err := tokenize.NewTrainer(opt)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fRead := bufio.NewScanner(fOpen)
-	for fRead.Scan() {
-		line := fRead.Text()
-		startConvert, _ := strconv.Atoi(strings.Split(string(line), " ")[15])
-		endConvert, _ := strconv.Atoi(strings.Split(string(line), " ")[16])
-		mfeConvert, _ := strconv.ParseFloat(strings.Split(string(line), " ")[18], 64)
-		tarFinderAdd = append(tarFinderAdd, tarFinderCap{
-			miRNA:    strings.Split(string(line), " ")[0],
-			sequence: strings.Split(string(line), " ")[11],
-			start:    startConvert,
-			end:      endConvert,
-			mfe:      mfeConvert,
-		})
-	}
-
-	type fastPredID struct {
-		id string
-	}
-
-	type fastPredSeq struct {
-		seq string
-	}
-
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+	f, err := os.Open(fastPred)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
+	r := fasta.NewReader(f)
+	n := 0
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
 		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
-		}
-	}
-
-	type tarFinderSeqStore struct {
-		miRNA      string
-		sequence   string
-		compseq    string
-		upstream   string
-		downstream string
-	}
-
-	tarFinderSeqAdd := []tarFinderSeqStore{}
-	for i := range tarFinderAdd {
-		for j := range fastID {
-			if tarFinderAdd[i].sequence == fastID[j].id {
-				tarFinderSeqAdd = append(tarFinderSeqAdd, tarFinderSeqStore{
-					miRNA:      tarFinderAdd[i].miRNA,
-					sequence:   tarFinderAdd[i].sequence,
-					compseq:    fastSeq[j].seq,
-					upstream:   fastSeq[j].seq[tarFinderAdd[i].start-upstream : tarFinderAdd[i].start],
-					downstream: fastSeq[j].seq[tarFinderAdd[i].end : tarFinderAdd[i].end+downstream],
-				})
-			}
+		if err != nil {
+			log.Fatalf("%s: %v", fastPred, err)
 		}
+		trainer.Add(rec.Seq)
+		n++
 	}
+	f.Close()
+	bpe := trainer.Train()
 
-	tarFinderWrite, err := os.Create("tarFinder.fasta")
+	out, err := os.Create(tokenizerPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer tarFinderWrite.Close()
-	for i := range tarFinderSeqAdd {
-		tarFinderWrite.WriteString(
-			tarFinderSeqAdd[i].miRNA + "\t" + tarFinderSeqAdd[i].sequence + "\t" + tarFinderSeqAdd[i].compseq + "\t" + tarFinderSeqAdd[i].upstream + "\t" + tarFinderSeqAdd[i].downstream,
-		)
+	if err := bpe.WriteJSON(out); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
 	}
+	log.Printf("%s: trained on %d sequences of %s; %d merges, vocabulary of %d tokens",
+		tokenizerPath, n, fastPred, len(bpe.Merges()), bpe.Vocab().Len())
+	return bpe
 }
 
-func psRobotFunc(cmd *cobra.Command, args []string) {
-	type psRobotCapture struct {
-		query        string
-		score        float64
-		queryStart   int
-		queryEnd     int
-		subjectStart int
-		subjectEnd   int
+// writeTokens tokenizes the sites of --input into a table of the site
+// ID, tokens, token IDs and segment IDs, and saves the vocabulary to
+// vocab unless it is empty.
+func writeTokens(t tokenize.Tokenizer, vocab string) {
+	seqs, err := output.ReadSequences(tokInput)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	psRobotC := []psRobotCapture{}
-	sortedID := []string{}
-	sortedScore := []float64{}
-
-	sortedQueryStart := []int{}
-	sortedQueryEnd := []int{}
-
-	sortedSubjectStart := []int{}
-	sortedSubjectEnd := []int{}
-
-	fOpen, err := os.Open(psRobotFile)
+	path := tokOutput
+	if path == "" {
+		path = strings.TrimSuffix(tokInput, filepath.Ext(tokInput)) + ".tokens.tsv"
+	}
+	f, err := os.Create(path)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fRead := bufio.NewScanner(fOpen)
-	for fRead.Scan() {
-		line := fRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			capStore := strings.Split(string(line), "\t")[0]
-			capScore := strings.Split(strings.Split(string(line), "\t")[1], ":")[1]
-			scoreFloat, _ := strconv.ParseFloat(capScore, 64)
-			sortedID = append(sortedID, strings.ReplaceAll(capStore, ">", ""))
-			sortedScore = append(sortedScore, scoreFloat)
-		}
-		if strings.HasPrefix(string(line), "Query") {
-			queryStart, _ := strconv.Atoi(
-				strings.Split(strings.Split(string(line), "\t")[1], "\t")[0],
-			)
-			queryEnd, _ := strconv.Atoi(
-				strings.Split(strings.Split(string(line), "\t")[1], "\t")[2],
-			)
-			sortedQueryStart = append(sortedQueryStart, queryStart)
-			sortedQueryEnd = append(sortedQueryEnd, queryEnd)
-		}
-		if strings.HasPrefix(string(line), "Sbjct") {
-			sbjctStart, _ := strconv.Atoi(
-				strings.Split(strings.Split(string(line), "\t")[1], "\t")[0],
-			)
-			sbjctEnd, _ := strconv.Atoi(
-				strings.Split(strings.Split(string(line), "\t")[1], "\t")[2],
-			)
-			sortedSubjectStart = append(sortedSubjectStart, sbjctStart)
-			sortedSubjectEnd = append(sortedSubjectEnd, sbjctEnd)
-		}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "id\ttokens\tinput_ids\ttoken_type_ids")
+	for _, s := range seqs {
+		e := tokenize.Encode(t, s.Upstream, s.Site, s.Downstream)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.ID, strings.Join(e.Tokens, " "), joinInts(e.IDs), joinInts(e.Segments))
 	}
-
-	for i := range sortedID {
-		psRobotC = append(psRobotC, psRobotCapture{
-			query:        sortedID[i],
-			score:        sortedScore[i],
-			queryStart:   sortedQueryStart[i],
-			queryEnd:     sortedQueryEnd[i],
-			subjectStart: sortedSubjectStart[i],
-			subjectEnd:   sortedSubjectEnd[i],
-		})
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
 	}
-
-	type fastPredID struct {
-		id string
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
 	}
 
-	type fastPredSeq struct {
-		seq string
+	log.Printf("%s: tokenized %d sites", path, len(seqs))
+	if vocab == "" {
+		return
 	}
-
-	fastID := []fastPredID{}
-	fastSeq := []fastPredSeq{}
-
-	fastaOpen, err := os.Open(fastPred)
+	vf, err := os.Create(vocab)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fastaRead := bufio.NewScanner(fastaOpen)
-	for fastaRead.Scan() {
-		line := fastaRead.Text()
-		if strings.HasPrefix(string(line), ">") {
-			fastID = append(fastID, fastPredID{
-				id: strings.ReplaceAll(string(line), ">", ""),
-			})
-		}
-		if !strings.HasPrefix(string(line), ">") {
-			fastSeq = append(fastSeq, fastPredSeq{
-				seq: string(line),
-			})
-		}
+	if _, err := t.Vocab().WriteTo(vf); err != nil {
+		log.Fatal(err)
 	}
-
-	type psRobotSeq struct {
-		query         string
-		score         float64
-		subjectString string
-		subjectComp   string
-		upstream      string
-		downstream    string
+	if err := vf.Close(); err != nil {
+		log.Fatal(err)
 	}
+	log.Printf("vocabulary of %d tokens written to %s", t.Vocab().Len(), vocab)
+}
 
-	finalpsRobotSeq := []psRobotSeq{}
-
-	for i := range psRobotC {
-		for j := range fastID {
-			if fastID[j].id == psRobotC[i].query {
-				finalpsRobotSeq = append(finalpsRobotSeq, psRobotSeq{
-					query:         psRobotC[i].query,
-					score:         psRobotC[i].score,
-					subjectString: fastSeq[j].seq[psRobotC[i].subjectStart:psRobotC[i].subjectEnd],
-					subjectComp:   fastSeq[j].seq,
-					upstream:      fastSeq[j].seq[psRobotC[i].subjectStart-upstream : psRobotC[i].subjectStart],
-					downstream:    fastSeq[j].seq[psRobotC[i].subjectEnd : psRobotC[i].subjectEnd+downstream],
-				})
-			}
-		}
+func joinInts(v []int) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = strconv.Itoa(x)
 	}
+	return strings.Join(s, " ")
 }
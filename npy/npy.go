@@ -0,0 +1,170 @@
+// Package npy writes NumPy .npy arrays and .npz archives of them, so
+// encoded sites can be read back with numpy.load.
+package npy
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Array is a C ordered array ready to be written. It is built with one
+// of the typed constructors, which check the data against the shape.
+type Array struct {
+	Shape []int
+	descr string
+	data  []byte
+}
+
+func newArray(shape []int, descr string, n, size int) (Array, error) {
+	want := 1
+	for _, d := range shape {
+		if d < 0 {
+			return Array{}, fmt.Errorf("npy: negative dimension in shape %v", shape)
+		}
+		want *= d
+	}
+	if n != want {
+		return Array{}, fmt.Errorf("npy: %d values do not fill shape %v", n, shape)
+	}
+	return Array{Shape: shape, descr: descr, data: make([]byte, 0, n*size)}, nil
+}
+
+// Uint8 returns a uint8 array of the given shape.
+func Uint8(shape []int, v []uint8) (Array, error) {
+	a, err := newArray(shape, "|u1", len(v), 1)
+	if err != nil {
+		return a, err
+	}
+	a.data = append(a.data, v...)
+	return a, nil
+}
+
+// Int32 returns a little endian int32 array of the given shape.
+func Int32(shape []int, v []int32) (Array, error) {
+	a, err := newArray(shape, "<i4", len(v), 4)
+	if err != nil {
+		return a, err
+	}
+	for _, x := range v {
+		a.data = binary.LittleEndian.AppendUint32(a.data, uint32(x))
+	}
+	return a, nil
+}
+
+// Int64 returns a little endian int64 array of the given shape.
+func Int64(shape []int, v []int64) (Array, error) {
+	a, err := newArray(shape, "<i8", len(v), 8)
+	if err != nil {
+		return a, err
+	}
+	for _, x := range v {
+		a.data = binary.LittleEndian.AppendUint64(a.data, uint64(x))
+	}
+	return a, nil
+}
+
+// Float64 returns a little endian float64 array of the given shape.
+func Float64(shape []int, v []float64) (Array, error) {
+	a, err := newArray(shape, "<f8", len(v), 8)
+	if err != nil {
+		return a, err
+	}
+	for _, x := range v {
+		a.data = binary.LittleEndian.AppendUint64(a.data, math.Float64bits(x))
+	}
+	return a, nil
+}
+
+// Strings returns a one dimensional fixed width unicode array, numpy's
+// <U dtype, as wide as the longest string. Unlike an object array it
+// loads without allow_pickle.
+func Strings(v []string) (Array, error) {
+	width := 1
+	for _, s := range v {
+		width = max(width, utf8.RuneCountInString(s))
+	}
+	a, err := newArray([]int{len(v)}, fmt.Sprintf("<U%d", width), len(v), 4*width)
+	if err != nil {
+		return a, err
+	}
+	for _, s := range v {
+		n := 0
+		for _, r := range s {
+			a.data = binary.LittleEndian.AppendUint32(a.data, uint32(r))
+			n++
+		}
+		for ; n < width; n++ {
+			a.data = binary.LittleEndian.AppendUint32(a.data, 0)
+		}
+	}
+	return a, nil
+}
+
+// header returns the version 1.0 preamble, padded so the data starts on
+// a 64 byte boundary as numpy expects.
+func (a Array) header() []byte {
+	dims := make([]string, len(a.Shape))
+	for i, d := range a.Shape {
+		dims[i] = fmt.Sprint(d)
+	}
+	shape := strings.Join(dims, ", ")
+	if len(a.Shape) == 1 {
+		shape += ","
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", a.descr, shape)
+	const preamble = 10
+	pad := 64 - (preamble+len(dict)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	dict += strings.Repeat(" ", pad) + "\n"
+	h := append([]byte("\x93NUMPY\x01\x00"), 0, 0)
+	binary.LittleEndian.PutUint16(h[8:], uint16(len(dict)))
+	return append(h, dict...)
+}
+
+// WriteTo writes the array in .npy format.
+func (a Array) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(a.header())
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(a.data)
+	return int64(n + m), err
+}
+
+// NPZWriter writes named arrays into a deflated .npz archive, the
+// layout numpy.savez_compressed produces.
+type NPZWriter struct {
+	z     *zip.Writer
+	names map[string]bool
+}
+
+// NewNPZ returns an NPZWriter writing to w.
+func NewNPZ(w io.Writer) *NPZWriter {
+	return &NPZWriter{z: zip.NewWriter(w), names: map[string]bool{}}
+}
+
+// Add stores a under name; numpy.load exposes it under the same key.
+func (nw *NPZWriter) Add(name string, a Array) error {
+	if nw.names[name] {
+		return fmt.Errorf("npz: array %q added twice", name)
+	}
+	nw.names[name] = true
+	f, err := nw.z.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = a.WriteTo(f)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (nw *NPZWriter) Close() error {
+	return nw.z.Close()
+}
@@ -0,0 +1,156 @@
+package npy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// golden builds the expected .npy bytes: magic, version 1.0, header
+// length, the dict padded with spaces to a 64 byte boundary, and data.
+func golden(dict string, headerLen int, data ...byte) []byte {
+	dict += strings.Repeat(" ", headerLen-10-len(dict)-1) + "\n"
+	b := []byte("\x93NUMPY\x01\x00")
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(dict)))
+	b = append(b, dict...)
+	return append(b, data...)
+}
+
+func TestWriteTo(t *testing.T) {
+	tests := []struct {
+		name string
+		a    func() (Array, error)
+		want []byte
+	}{
+		{
+			"int32",
+			func() (Array, error) { return Int32([]int{2}, []int32{1, -2}) },
+			golden("{'descr': '<i4', 'fortran_order': False, 'shape': (2,), }", 128,
+				1, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff),
+		},
+		{
+			"uint8 matrix",
+			func() (Array, error) { return Uint8([]int{2, 3}, []uint8{1, 2, 3, 4, 5, 6}) },
+			golden("{'descr': '|u1', 'fortran_order': False, 'shape': (2, 3), }", 128,
+				1, 2, 3, 4, 5, 6),
+		},
+		{
+			"empty",
+			func() (Array, error) { return Int64([]int{0, 4}, nil) },
+			golden("{'descr': '<i8', 'fortran_order': False, 'shape': (0, 4), }", 128),
+		},
+		{
+			"float64",
+			func() (Array, error) { return Float64([]int{1}, []float64{1.5}) },
+			golden("{'descr': '<f8', 'fortran_order': False, 'shape': (1,), }", 128,
+				0, 0, 0, 0, 0, 0, 0xf8, 0x3f),
+		},
+		{
+			// Strings are padded with NUL code points to the longest,
+			// counted in runes.
+			"unicode",
+			func() (Array, error) { return Strings([]string{"ab", "µ", ""}) },
+			golden("{'descr': '<U2', 'fortran_order': False, 'shape': (3,), }", 128,
+				'a', 0, 0, 0, 'b', 0, 0, 0,
+				0xb5, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0),
+		},
+		{
+			"no strings",
+			func() (Array, error) { return Strings(nil) },
+			golden("{'descr': '<U1', 'fortran_order': False, 'shape': (0,), }", 128),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := tt.a()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			n, err := a.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) || !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("wrote %d bytes\n%q\nwant\n%q", n, buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderPadding(t *testing.T) {
+	// Long shapes push the dict past 64 bytes; the data must still
+	// start on a 64 byte boundary.
+	shape := []int{1}
+	for i := 0; i < 40; i++ {
+		a, err := Uint8(shape, make([]uint8, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := a.header()
+		if len(h)%64 != 0 || h[len(h)-1] != '\n' || int(binary.LittleEndian.Uint16(h[8:])) != len(h)-10 {
+			t.Fatalf("shape %v: header of %d bytes: %q", shape, len(h), h)
+		}
+		shape = append(shape, 1)
+	}
+}
+
+func TestShapeMismatch(t *testing.T) {
+	if _, err := Int32([]int{2, 2}, []int32{1, 2, 3}); err == nil || !strings.Contains(err.Error(), "do not fill shape") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := Uint8([]int{-1}, nil); err == nil || !strings.Contains(err.Error(), "negative dimension") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestNPZ(t *testing.T) {
+	label, _ := Int32([]int{2}, []int32{1, 0})
+	tool, _ := Strings([]string{"TAPIR", "psRobot"})
+	var buf bytes.Buffer
+	z := NewNPZ(&buf)
+	if err := z.Add("label", label); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Add("tool", tool); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Add("label", label); err == nil {
+		t.Error("adding label twice succeeded")
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Array{"label.npy": label, "tool.npy": tool}
+	if len(r.File) != len(want) {
+		t.Fatalf("got %d members, want %d", len(r.File), len(want))
+	}
+	for _, f := range r.File {
+		if f.Method != zip.Deflate {
+			t.Errorf("%s: method %d, want deflate", f.Name, f.Method)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var exp bytes.Buffer
+		want[f.Name].WriteTo(&exp)
+		if !bytes.Equal(got, exp.Bytes()) {
+			t.Errorf("%s: got %q, want %q", f.Name, got, exp.Bytes())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"sort"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/go-microRNAs/predict"
+)
+
+// batchRows is how many sites the columnar writers gather per record
+// batch.
+const batchRows = 64 * 1024
+
+// siteFields are the typed columns of the site table shared by the
+// Parquet and Arrow writers. They follow the TSV columns; cleavage and
+// the genome columns are null when unknown and features maps feature
+// names to values.
+var siteFields = []arrow.Field{
+	{Name: "tool", Type: arrow.BinaryTypes.String},
+	{Name: "mirna_id", Type: arrow.BinaryTypes.String},
+	{Name: "mirna_seq", Type: arrow.BinaryTypes.String},
+	{Name: "target_id", Type: arrow.BinaryTypes.String},
+	{Name: "start", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "end", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "strand", Type: arrow.BinaryTypes.String},
+	{Name: "cleavage", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "mirna_aligned", Type: arrow.BinaryTypes.String},
+	{Name: "target_aligned", Type: arrow.BinaryTypes.String},
+	{Name: "match", Type: arrow.BinaryTypes.String},
+	{Name: "site", Type: arrow.BinaryTypes.String},
+	{Name: "upstream", Type: arrow.BinaryTypes.String},
+	{Name: "downstream", Type: arrow.BinaryTypes.String},
+	{Name: "upstream_pad", Type: arrow.PrimitiveTypes.Int32},
+	{Name: "downstream_pad", Type: arrow.PrimitiveTypes.Int32},
+	{Name: "chrom", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "genome_start", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "genome_end", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "genome_strand", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "features", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Float64)},
+}
+
+// siteBuilder appends sites to the columns of siteFields, in order, at
+// the start of an arrow record builder.
+type siteBuilder struct {
+	rb  *array.RecordBuilder
+	opt Options
+}
+
+func newSiteBuilder(schema *arrow.Schema, opt Options) *siteBuilder {
+	return &siteBuilder{rb: array.NewRecordBuilder(memory.DefaultAllocator, schema), opt: opt}
+}
+
+func (b *siteBuilder) append(s predict.TargetSite) {
+	start, end := b.opt.Coords.FromInternal(s.Start, s.End)
+	str := func(i int, v string) { b.rb.Field(i).(*array.StringBuilder).Append(v) }
+	i64 := func(i int, v int) { b.rb.Field(i).(*array.Int64Builder).Append(int64(v)) }
+	i32 := func(i int, v int) { b.rb.Field(i).(*array.Int32Builder).Append(int32(v)) }
+
+	str(0, s.Tool)
+	str(1, s.MiRNAID)
+	str(2, s.MiRNASeq)
+	str(3, s.TargetID)
+	i64(4, start)
+	i64(5, end)
+	str(6, s.Strand)
+	if s.Cleavage >= 0 {
+		c, _ := b.opt.Coords.FromInternal(s.Cleavage, s.Cleavage+1)
+		i64(7, c)
+	} else {
+		b.rb.Field(7).AppendNull()
+	}
+	b.rb.Field(8).(*array.Float64Builder).Append(s.Score)
+	str(9, s.MiRNAAligned)
+	str(10, s.TargetAligned)
+	str(11, s.Match)
+	str(12, s.Sequence)
+	str(13, s.Upstream)
+	str(14, s.Downstream)
+	i32(15, s.UpstreamPad)
+	i32(16, s.DownstreamPad)
+	if g := s.Genome; g != nil {
+		gStart, gEnd := b.opt.Coords.FromInternal(g.Start, g.End)
+		str(17, g.Chrom)
+		i64(18, gStart)
+		i64(19, gEnd)
+		str(20, g.Strand)
+	} else {
+		for i := 17; i <= 20; i++ {
+			b.rb.Field(i).AppendNull()
+		}
+	}
+	mb := b.rb.Field(21).(*array.MapBuilder)
+	mb.Append(true)
+	names := make([]string, 0, len(s.Features))
+	for name := range s.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mb.KeyBuilder().(*array.StringBuilder).Append(name)
+		mb.ItemBuilder().(*array.Float64Builder).Append(s.Features[name])
+	}
+}
@@ -0,0 +1,262 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-microRNAs/predict"
+	"github.com/go-microRNAs/region"
+)
+
+// locate places a site on the genome when its target was a genome
+// region and on the target sequence otherwise.
+func locate(s predict.TargetSite) region.Interval {
+	if s.Genome != nil {
+		return *s.Genome
+	}
+	return region.Interval{Chrom: s.TargetID, Start: s.Start, End: s.End, Strand: bedStrand(s.Strand)}
+}
+
+func bedStrand(strand string) string {
+	if strand == "+" || strand == "-" {
+		return strand
+	}
+	return "."
+}
+
+// bedName is the site's miRNA with any whitespace removed, since BED
+// columns may not contain it, followed by the tool score as "|score"
+// when the tool gives one.
+func bedName(s predict.TargetSite) string {
+	name := strings.Join(strings.Fields(s.MiRNAID), "_")
+	if !s.Scored() {
+		return name
+	}
+	return name + "|" + formatFloat(s.Score)
+}
+
+// bedScore scales the tool score into the 0..1000 integers BED allows.
+// Every tool that scores sites counts mismatch penalties, lower being
+// better, so a perfect site is 1000 and each penalty point takes off
+// 100. Sites without a score get 0.
+func bedScore(s predict.TargetSite) int {
+	if !s.Scored() {
+		return 0
+	}
+	return int(math.Round(max(0, min(1000, 1000-100*s.Score))))
+}
+
+// BEDWriter writes sites as BED6, or BED12 with one block per stretch of
+// target bases paired with the miRNA. BED is always 0-based half-open.
+type BEDWriter struct {
+	w      io.Writer
+	blocks bool
+}
+
+// NewBED6 returns a BEDWriter writing chrom, start, end, miRNA with the
+// tool score, scaled score and strand.
+func NewBED6(w io.Writer) *BEDWriter {
+	return &BEDWriter{w: w}
+}
+
+// NewBED12 returns a BEDWriter that also writes the site as thick and
+// splits it into blocks at target bulges.
+func NewBED12(w io.Writer) *BEDWriter {
+	return &BEDWriter{w: w, blocks: true}
+}
+
+// Write writes one BED line for the site.
+func (bw *BEDWriter) Write(s predict.TargetSite) error {
+	iv := locate(s)
+	cells := []string{
+		iv.Chrom,
+		strconv.Itoa(iv.Start),
+		strconv.Itoa(iv.End),
+		bedName(s),
+		strconv.Itoa(bedScore(s)),
+		iv.Strand,
+	}
+	if bw.blocks {
+		starts, sizes := blocks(s, iv)
+		cells = append(cells,
+			strconv.Itoa(iv.Start),
+			strconv.Itoa(iv.End),
+			"0",
+			strconv.Itoa(len(starts)),
+			joinInts(sizes),
+			joinInts(starts),
+		)
+	}
+	_, err := io.WriteString(bw.w, strings.Join(cells, "\t")+"\n")
+	return err
+}
+
+// Close is a no-op; the caller owns the underlying writer.
+func (bw *BEDWriter) Close() error {
+	return nil
+}
+
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",") + ","
+}
+
+// blocks splits the site into the runs of target bases that pair with a
+// miRNA base, reading the target alignment 5' to 3'. Bulged target bases
+// (a gap in the miRNA alignment) separate blocks. Unpaired bases at
+// either end stay inside the first and last block, and a site without a
+// usable alignment is a single block.
+func blocks(s predict.TargetSite, iv region.Interval) (starts, sizes []int) {
+	n := iv.Len()
+	tAln, mAln := s.TargetAligned, s.MiRNAAligned
+	if n <= 0 || tAln == "" || len(tAln) != len(mAln) || len(strings.ReplaceAll(tAln, "-", "")) != n {
+		return []int{0}, []int{max(n, 0)}
+	}
+	paired := make([]bool, 0, n)
+	for i := 0; i < len(tAln); i++ {
+		if tAln[i] == '-' {
+			continue
+		}
+		paired = append(paired, mAln[i] != '-')
+	}
+	if iv.Strand == "-" {
+		for i, j := 0, len(paired)-1; i < j; i, j = i+1, j-1 {
+			paired[i], paired[j] = paired[j], paired[i]
+		}
+	}
+	first, last := 0, n-1
+	for first < n && !paired[first] {
+		first++
+	}
+	for last >= 0 && !paired[last] {
+		last--
+	}
+	if first > last {
+		return []int{0}, []int{n}
+	}
+	for i := 0; i < first; i++ {
+		paired[i] = true
+	}
+	for i := last + 1; i < n; i++ {
+		paired[i] = true
+	}
+	for i := 0; i < n; {
+		if !paired[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < n && paired[j] {
+			j++
+		}
+		starts = append(starts, i)
+		sizes = append(sizes, j-i)
+		i = j
+	}
+	return starts, sizes
+}
+
+// GFF3Writer writes sites as GFF3 miRNA_target_site features with the
+// miRNA, target and tool values as attributes. GFF3 is always 1-based.
+type GFF3Writer struct {
+	w      io.Writer
+	n      int
+	header bool
+}
+
+// NewGFF3 returns a GFF3Writer writing to w.
+func NewGFF3(w io.Writer) *GFF3Writer {
+	return &GFF3Writer{w: w}
+}
+
+// gffEscape percent-encodes the characters GFF3 reserves in columns and
+// attribute values.
+func gffEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ';' || c == '=' || c == '&' || c == ',' || c == '%' || c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Write writes one feature line, preceded by the version pragma on first
+// use.
+func (gw *GFF3Writer) Write(s predict.TargetSite) error {
+	if err := gw.writeHeader(); err != nil {
+		return err
+	}
+	gw.n++
+	iv := locate(s)
+	attrs := [][2]string{
+		{"ID", fmt.Sprintf("site%d", gw.n)},
+		{"Name", s.MiRNAID},
+		{"miRNA", s.MiRNAID},
+		{"target", s.TargetID},
+		{"tool", s.Tool},
+	}
+	if s.MiRNASeq != "" {
+		attrs = append(attrs, [2]string{"miRNA_seq", s.MiRNASeq})
+	}
+	if s.Sequence != "" {
+		attrs = append(attrs, [2]string{"site_seq", s.Sequence})
+	}
+	if s.Genome != nil {
+		attrs = append(attrs, [2]string{"target_start", strconv.Itoa(s.Start + 1)}, [2]string{"target_end", strconv.Itoa(s.End)})
+	}
+	names := make([]string, 0, len(s.Features))
+	for name := range s.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attrs = append(attrs, [2]string{name, formatFloat(s.Features[name])})
+	}
+	parts := make([]string, len(attrs))
+	for i, kv := range attrs {
+		parts[i] = gffEscape(kv[0]) + "=" + gffEscape(kv[1])
+	}
+	score := "."
+	if s.Scored() {
+		score = formatFloat(s.Score)
+	}
+	cells := []string{
+		gffEscape(iv.Chrom),
+		gffEscape(s.Tool),
+		"miRNA_target_site",
+		strconv.Itoa(iv.Start + 1),
+		strconv.Itoa(iv.End),
+		score,
+		iv.Strand,
+		".",
+		strings.Join(parts, ";"),
+	}
+	_, err := io.WriteString(gw.w, strings.Join(cells, "\t")+"\n")
+	return err
+}
+
+func (gw *GFF3Writer) writeHeader() error {
+	if gw.header {
+		return nil
+	}
+	gw.header = true
+	_, err := io.WriteString(gw.w, "##gff-version 3\n")
+	return err
+}
+
+// Close writes the version pragma if no site was written.
+func (gw *GFF3Writer) Close() error {
+	return gw.writeHeader()
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-microRNAs/predict"
+)
+
+func TestBEDScore(t *testing.T) {
+	tests := []struct {
+		name string
+		site predict.TargetSite
+		bed  string
+		gff  string
+	}{
+		{"perfect", predict.TargetSite{Tool: predict.ToolTargetFinder, MiRNAID: "miR399a", Score: 0}, "miR399a|0\t1000", "0"},
+		{"penalties", predict.TargetSite{Tool: predict.ToolPsRNATarget, MiRNAID: "miR 399a", Score: 2.5}, "miR_399a|2.5\t750", "2.5"},
+		{"clamped", predict.TargetSite{Tool: predict.ToolTapir, MiRNAID: "miR399a", Score: 12}, "miR399a|12\t0", "12"},
+		{"no score", predict.TargetSite{Tool: predict.ToolPsRNAMap, MiRNAID: "read1"}, "read1\t0", "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.site
+			s.TargetID, s.Start, s.End, s.Strand = "AT2G33770.1", 10, 31, "+"
+			var bed, gff bytes.Buffer
+			if err := NewBED6(&bed).Write(s); err != nil {
+				t.Fatal(err)
+			}
+			if want := "AT2G33770.1\t10\t31\t" + tt.bed + "\t+\n"; bed.String() != want {
+				t.Errorf("got BED %q, want %q", bed.String(), want)
+			}
+			if err := NewGFF3(&gff).Write(s); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(gff.String(), "\n")
+			if cells := strings.Split(lines[1], "\t"); cells[5] != tt.gff {
+				t.Errorf("got GFF3 score %q, want %q", cells[5], tt.gff)
+			}
+		})
+	}
+}
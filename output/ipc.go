@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/go-microRNAs/encode"
+	"github.com/go-microRNAs/predict"
+)
+
+// ArrowWriter writes the site table as Arrow IPC, either the file
+// format, which is Feather v2 and can be memory mapped, or the stream
+// format. Next to the site columns it adds
+//
+//	length  int32, bases of upstream+site+downstream before padding
+//	x       upstream+site+downstream encoded as in the npz output:
+//	        fixed_size_list<uint8>[L] integer tokens, or
+//	        fixed_size_list<fixed_size_list<uint8>[4]>[L] one-hot rows
+//
+// L is the longest site with its flanks, so sites are buffered until
+// Close. Sites cut to windows are encoded as their windows, and the
+// attention_mask and segment_ids columns (fixed_size_list<uint8>[L]) and
+// window_offset (int32) are added as in the npz output.
+type ArrowWriter struct {
+	path   string
+	opt    Options
+	stream bool
+	sites  []predict.TargetSite
+}
+
+func openArrow(stream bool) func(string, Options) (Writer, error) {
+	return func(path string, opt Options) (Writer, error) {
+		return &ArrowWriter{path: path, opt: opt, stream: stream}, nil
+	}
+}
+
+// Write buffers the site.
+func (aw *ArrowWriter) Write(s predict.TargetSite) error {
+	aw.sites = append(aw.sites, s)
+	return nil
+}
+
+// tensorType is the type of the x column for sequences of length L.
+func tensorType(scheme encode.Scheme, length int) arrow.DataType {
+	if scheme == encode.Integer {
+		return arrow.FixedSizeListOf(int32(length), arrow.PrimitiveTypes.Uint8)
+	}
+	return arrow.FixedSizeListOf(int32(length), arrow.FixedSizeListOf(encode.Channels, arrow.PrimitiveTypes.Uint8))
+}
+
+// Close writes the buffered sites in batches.
+func (aw *ArrowWriter) Close() error {
+	// Arrow has no fixed size lists of size zero.
+	length := tensorLength(aw.sites, 1)
+	fields := append(append([]arrow.Field(nil), siteFields...),
+		arrow.Field{Name: "length", Type: arrow.PrimitiveTypes.Int32},
+		arrow.Field{Name: "x", Type: tensorType(aw.opt.Encoding, length)},
+	)
+	win := windowed(aw.sites)
+	if win {
+		fields = append(fields,
+			arrow.Field{Name: "attention_mask", Type: tensorType(encode.Integer, length)},
+			arrow.Field{Name: "segment_ids", Type: tensorType(encode.Integer, length)},
+			arrow.Field{Name: "window_offset", Type: arrow.PrimitiveTypes.Int32},
+		)
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	f, err := os.Create(aw.path)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(f)
+	var iw interface {
+		Write(arrow.Record) error
+		Close() error
+	}
+	if aw.stream {
+		iw = ipc.NewWriter(buf, ipc.WithSchema(schema))
+	} else if iw, err = ipc.NewFileWriter(buf, ipc.WithSchema(schema)); err != nil {
+		f.Close()
+		return err
+	}
+
+	b := newSiteBuilder(schema, aw.opt)
+	defer b.rb.Release()
+	lengths := b.rb.Field(len(siteFields)).(*array.Int32Builder)
+	x := b.rb.Field(len(siteFields) + 1).(*array.FixedSizeListBuilder)
+	var tokens []uint8
+	for i, s := range aw.sites {
+		seq, mask := tensorInput(s)
+		b.append(s)
+		lengths.Append(int32(len(s.Upstream) + len(s.Sequence) + len(s.Downstream)))
+		tokens = aw.opt.Encoding.AppendMasked(tokens[:0], seq, mask, length)
+		appendTensor(x, tokens, aw.opt.Encoding)
+		if win {
+			col := len(siteFields) + 2
+			appendTensor(b.rb.Field(col).(*array.FixedSizeListBuilder), s.Window.Mask, encode.Integer)
+			appendTensor(b.rb.Field(col+1).(*array.FixedSizeListBuilder), s.Window.Segments, encode.Integer)
+			b.rb.Field(col + 2).(*array.Int32Builder).Append(int32(s.Window.Offset))
+		}
+		if (i+1)%batchRows == 0 || i == len(aw.sites)-1 {
+			rec := b.rb.NewRecord()
+			err = iw.Write(rec)
+			rec.Release()
+			if err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err := iw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// appendTensor appends one encoded sequence to the x column.
+func appendTensor(x *array.FixedSizeListBuilder, tokens []uint8, scheme encode.Scheme) {
+	x.Append(true)
+	if scheme == encode.Integer {
+		x.ValueBuilder().(*array.Uint8Builder).AppendValues(tokens, nil)
+		return
+	}
+	rows := x.ValueBuilder().(*array.FixedSizeListBuilder)
+	values := rows.ValueBuilder().(*array.Uint8Builder)
+	for i := 0; i < len(tokens); i += encode.Channels {
+		rows.Append(true)
+		values.AppendValues(tokens[i:i+encode.Channels], nil)
+	}
+}
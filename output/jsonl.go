@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/go-microRNAs/predict"
+)
+
+// jsonSite is the shape of one JSON Lines record. Positions follow
+// Options.Coords and the record says which numbering it used.
+type jsonSite struct {
+	Tool          string             `json:"tool"`
+	MiRNAID       string             `json:"mirna_id"`
+	MiRNASeq      string             `json:"mirna_seq,omitempty"`
+	TargetID      string             `json:"target_id"`
+	Coords        string             `json:"coords"`
+	Start         int                `json:"start"`
+	End           int                `json:"end"`
+	Strand        string             `json:"strand"`
+	Cleavage      *int               `json:"cleavage,omitempty"`
+	Score         *float64           `json:"score"`
+	MiRNAAligned  string             `json:"mirna_aligned,omitempty"`
+	TargetAligned string             `json:"target_aligned,omitempty"`
+	Match         string             `json:"match,omitempty"`
+	Site          string             `json:"site"`
+	Upstream      string             `json:"upstream"`
+	Downstream    string             `json:"downstream"`
+	UpstreamPad   int                `json:"upstream_pad"`
+	DownstreamPad int                `json:"downstream_pad"`
+	Genome        *jsonGenome        `json:"genome,omitempty"`
+	Window        *jsonWindow        `json:"window,omitempty"`
+	Features      map[string]float64 `json:"features,omitempty"`
+	Raw           map[string]string  `json:"raw,omitempty"`
+	Source        jsonSource         `json:"source"`
+}
+
+type jsonGenome struct {
+	Chrom  string `json:"chrom"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Strand string `json:"strand"`
+}
+
+// jsonWindow writes the masks as number arrays; []uint8 would
+// otherwise be encoded as base64.
+type jsonWindow struct {
+	Sequence      string `json:"sequence"`
+	AttentionMask []int  `json:"attention_mask"`
+	SegmentIDs    []int  `json:"segment_ids"`
+	Offset        int    `json:"offset"`
+}
+
+type jsonSource struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// JSONLWriter writes one JSON object per line holding the normalized
+// site, its flanks and the record of the prediction file it came from.
+type JSONLWriter struct {
+	enc *json.Encoder
+	opt Options
+}
+
+// NewJSONL returns a JSONLWriter writing to w.
+func NewJSONL(w io.Writer, opt Options) *JSONLWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLWriter{enc: enc, opt: opt}
+}
+
+// Write encodes the site as a single line.
+func (jw *JSONLWriter) Write(s predict.TargetSite) error {
+	start, end := jw.opt.Coords.FromInternal(s.Start, s.End)
+	rec := jsonSite{
+		Tool:          s.Tool,
+		MiRNAID:       s.MiRNAID,
+		MiRNASeq:      s.MiRNASeq,
+		TargetID:      s.TargetID,
+		Coords:        jw.opt.Coords.String(),
+		Start:         start,
+		End:           end,
+		Strand:        s.Strand,
+		MiRNAAligned:  s.MiRNAAligned,
+		TargetAligned: s.TargetAligned,
+		Match:         s.Match,
+		Site:          s.Sequence,
+		Upstream:      s.Upstream,
+		Downstream:    s.Downstream,
+		UpstreamPad:   s.UpstreamPad,
+		DownstreamPad: s.DownstreamPad,
+		Raw:           s.Raw,
+		Source:        jsonSource{File: s.Origin.File, Line: s.Origin.Line, Text: s.Origin.Text},
+	}
+	if s.Cleavage >= 0 {
+		c, _ := jw.opt.Coords.FromInternal(s.Cleavage, s.Cleavage+1)
+		rec.Cleavage = &c
+	}
+	// encoding/json rejects NaN and infinities, so they become null or
+	// are left out.
+	if !math.IsNaN(s.Score) && !math.IsInf(s.Score, 0) {
+		score := s.Score
+		rec.Score = &score
+	}
+	if len(s.Features) > 0 {
+		rec.Features = make(map[string]float64, len(s.Features))
+		for k, v := range s.Features {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				rec.Features[k] = v
+			}
+		}
+	}
+	if s.Genome != nil {
+		gStart, gEnd := jw.opt.Coords.FromInternal(s.Genome.Start, s.Genome.End)
+		rec.Genome = &jsonGenome{Chrom: s.Genome.Chrom, Start: gStart, End: gEnd, Strand: s.Genome.Strand}
+	}
+	if w := s.Window; w != nil {
+		rec.Window = &jsonWindow{Sequence: w.Sequence, Offset: w.Offset}
+		for i := range w.Mask {
+			rec.Window.AttentionMask = append(rec.Window.AttentionMask, int(w.Mask[i]))
+			rec.Window.SegmentIDs = append(rec.Window.SegmentIDs, int(w.Segments[i]))
+		}
+	}
+	return jw.enc.Encode(rec)
+}
+
+// Close is a no-op; the caller owns the underlying writer.
+func (jw *JSONLWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-microRNAs/encode"
+	"github.com/go-microRNAs/npy"
+	"github.com/go-microRNAs/predict"
+)
+
+// NPZWriter collects the sites and on Close writes them as a .npz
+// archive of arrays sharing their first axis:
+//
+//	x               uint8 N×L×4 one-hot, or N×L integer tokens
+//	label           int32 N, Options.Label for every site
+//	length          int32 N, bases of upstream+site+downstream before padding
+//	upstream_len    int32 N
+//	site_len        int32 N
+//	downstream_len  int32 N
+//	start, end      int64 N, in Options.Coords numbering
+//	score           float64 N
+//	tool, mirna_id, target_id, strand   unicode N
+//
+// L is the longest upstream+site+downstream; shorter rows are padded at
+// the end with encode.Pad. When the sites were cut to windows x holds
+// the windows, L is the window length and three arrays are added:
+//
+//	attention_mask  uint8 N×L, 1 for bases and 0 for padding
+//	segment_ids     uint8 N×L, 0 upstream, 1 site, 2 downstream
+//	window_offset   int32 N, position of the window in upstream+site+downstream
+type NPZWriter struct {
+	path  string
+	opt   Options
+	sites []predict.TargetSite
+}
+
+func openNPZ(path string, opt Options) (Writer, error) {
+	return &NPZWriter{path: path, opt: opt}, nil
+}
+
+// Write buffers the site; the arrays need every site to be sized.
+func (nw *NPZWriter) Write(s predict.TargetSite) error {
+	nw.sites = append(nw.sites, s)
+	return nil
+}
+
+// Close encodes the buffered sites and writes the archive.
+func (nw *NPZWriter) Close() error {
+	n := len(nw.sites)
+	length := tensorLength(nw.sites, 0)
+	var (
+		x          = make([]uint8, 0, n*length*nw.opt.Encoding.Width())
+		label      = make([]int32, n)
+		seqLen     = make([]int32, n)
+		upLen      = make([]int32, n)
+		siteLen    = make([]int32, n)
+		downLen    = make([]int32, n)
+		start      = make([]int64, n)
+		end        = make([]int64, n)
+		score      = make([]float64, n)
+		tool       = make([]string, n)
+		mirna      = make([]string, n)
+		target     = make([]string, n)
+		strand     = make([]string, n)
+		xShape     = []int{n, length}
+		vectorSize = []int{n}
+	)
+	if nw.opt.Encoding == encode.OneHot {
+		xShape = append(xShape, encode.Channels)
+	}
+	for i, s := range nw.sites {
+		seq, mask := tensorInput(s)
+		x = nw.opt.Encoding.AppendMasked(x, seq, mask, length)
+		label[i] = int32(nw.opt.Label)
+		seqLen[i] = int32(len(s.Upstream) + len(s.Sequence) + len(s.Downstream))
+		upLen[i] = int32(len(s.Upstream))
+		siteLen[i] = int32(len(s.Sequence))
+		downLen[i] = int32(len(s.Downstream))
+		st, en := nw.opt.Coords.FromInternal(s.Start, s.End)
+		start[i], end[i] = int64(st), int64(en)
+		score[i] = s.Score
+		tool[i], mirna[i], target[i], strand[i] = s.Tool, s.MiRNAID, s.TargetID, s.Strand
+	}
+
+	var err error
+	check := func(a npy.Array, aerr error) npy.Array {
+		if err == nil {
+			err = aerr
+		}
+		return a
+	}
+	arrays := []namedArray{
+		{"x", check(npy.Uint8(xShape, x))},
+		{"label", check(npy.Int32(vectorSize, label))},
+		{"length", check(npy.Int32(vectorSize, seqLen))},
+		{"upstream_len", check(npy.Int32(vectorSize, upLen))},
+		{"site_len", check(npy.Int32(vectorSize, siteLen))},
+		{"downstream_len", check(npy.Int32(vectorSize, downLen))},
+		{"start", check(npy.Int64(vectorSize, start))},
+		{"end", check(npy.Int64(vectorSize, end))},
+		{"score", check(npy.Float64(vectorSize, score))},
+		{"tool", check(npy.Strings(tool))},
+		{"mirna_id", check(npy.Strings(mirna))},
+		{"target_id", check(npy.Strings(target))},
+		{"strand", check(npy.Strings(strand))},
+	}
+	if windowed(nw.sites) {
+		mask, segments, offsets := windowColumns(nw.sites)
+		arrays = append(arrays,
+			namedArray{"attention_mask", check(npy.Uint8([]int{n, length}, mask))},
+			namedArray{"segment_ids", check(npy.Uint8([]int{n, length}, segments))},
+			namedArray{"window_offset", check(npy.Int32(vectorSize, offsets))},
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", nw.path, err)
+	}
+	return writeNPZ(nw.path, arrays)
+}
+
+type namedArray struct {
+	name string
+	a    npy.Array
+}
+
+func writeNPZ(path string, arrays []namedArray) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	z := npy.NewNPZ(f)
+	for _, na := range arrays {
+		if err := z.Add(na.name, na.a); err != nil {
+			f.Close()
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
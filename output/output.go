@@ -0,0 +1,113 @@
+// Package output writes extracted target sites in the formats selected
+// with --format.
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/go-microRNAs/encode"
+	"github.com/go-microRNAs/predict"
+)
+
+// Writer receives extracted sites one at a time. Close flushes anything
+// buffered and releases the files the writer created.
+type Writer interface {
+	Write(s predict.TargetSite) error
+	Close() error
+}
+
+// Options are shared by every format.
+type Options struct {
+	// Coords is the numbering written for site positions by the text
+	// formats. Sites are held 0-based half-open internally; BED and GFF3
+	// always use their own fixed numbering.
+	Coords predict.Convention
+	// Encoding and Label are used by the tensor formats: how sequences
+	// are turned into numbers and the label given to every site.
+	Encoding encode.Scheme
+	Label    int
+	// Shards splits tfrecord output over that many files.
+	Shards int
+}
+
+type format struct {
+	ext  string
+	open func(path string, opt Options) (Writer, error)
+}
+
+var formats = map[string]format{
+	"fasta":    {".fasta", streamFile(func(w io.Writer, opt Options) Writer { return NewFASTA(w, opt) })},
+	"tsv":      {".tsv", streamFile(func(w io.Writer, opt Options) Writer { return NewTSV(w, opt) })},
+	"bed6":     {".bed", streamFile(func(w io.Writer, _ Options) Writer { return NewBED6(w) })},
+	"bed12":    {".bed", streamFile(func(w io.Writer, _ Options) Writer { return NewBED12(w) })},
+	"gff3":     {".gff3", streamFile(func(w io.Writer, _ Options) Writer { return NewGFF3(w) })},
+	"jsonl":    {".jsonl", streamFile(func(w io.Writer, opt Options) Writer { return NewJSONL(w, opt) })},
+	"npz":      {".npz", openNPZ},
+	"tfrecord": {".tfrecord", openTFRecord},
+	"parquet":  {".parquet", openParquet},
+	"arrow":    {".arrow", openArrow(false)},
+	"arrows":   {".arrows", openArrow(true)},
+	"sqlite":   {".sqlite", openSQLite},
+}
+
+// Formats lists the names accepted by Create.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Extension returns the file extension used for a format.
+func Extension(name string) (string, error) {
+	f, ok := formats[name]
+	if !ok {
+		return "", fmt.Errorf("unknown output format %q (want one of %v)", name, Formats())
+	}
+	return f.ext, nil
+}
+
+// Create opens a Writer for the named format at path.
+func Create(name, path string, opt Options) (Writer, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (want one of %v)", name, Formats())
+	}
+	return f.open(path, opt)
+}
+
+// fileWriter closes the file under a streaming Writer.
+type fileWriter struct {
+	Writer
+	f *os.File
+	b *bufio.Writer
+}
+
+func (fw *fileWriter) Close() error {
+	err := fw.Writer.Close()
+	if ferr := fw.b.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := fw.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// streamFile adapts a constructor over io.Writer into one over a path.
+func streamFile(newWriter func(io.Writer, Options) Writer) func(string, Options) (Writer, error) {
+	return func(path string, opt Options) (Writer, error) {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		b := bufio.NewWriter(f)
+		return &fileWriter{Writer: newWriter(b, opt), f: f, b: b}, nil
+	}
+}
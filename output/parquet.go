@@ -0,0 +1,64 @@
+package output
+
+import (
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/go-microRNAs/predict"
+)
+
+// ParquetWriter writes the site table as a Snappy compressed Parquet
+// file, one row group per batch of sites.
+type ParquetWriter struct {
+	fw   *pqarrow.FileWriter
+	b    *siteBuilder
+	rows int
+}
+
+func openParquet(path string, opt Options) (Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	schema := arrow.NewSchema(siteFields, nil)
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(schema, f, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &ParquetWriter{fw: fw, b: newSiteBuilder(schema, opt)}, nil
+}
+
+// Write adds the site as a row, writing a row group once a batch fills.
+func (pw *ParquetWriter) Write(s predict.TargetSite) error {
+	pw.b.append(s)
+	pw.rows++
+	if pw.rows < batchRows {
+		return nil
+	}
+	return pw.flush()
+}
+
+func (pw *ParquetWriter) flush() error {
+	rec := pw.b.rb.NewRecord()
+	defer rec.Release()
+	pw.rows = 0
+	return pw.fw.Write(rec)
+}
+
+// Close writes the remaining rows and the footer and closes the file.
+func (pw *ParquetWriter) Close() error {
+	defer pw.b.rb.Release()
+	var err error
+	if pw.rows > 0 {
+		err = pw.flush()
+	}
+	if cerr := pw.fw.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-microRNAs/fasta"
+)
+
+// SiteSequence is a site read back from fasta, tsv or jsonl output.
+type SiteSequence struct {
+	// ID names the site as the fasta header does,
+	// miRNA|target:start-end(strand).
+	ID         string
+	Upstream   string
+	Site       string
+	Downstream string
+}
+
+// ReadSequences reads the sites of a file written with --format fasta,
+// tsv or jsonl, picking the format from the file extension.
+func ReadSequences(path string) ([]SiteSequence, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var seqs []SiteSequence
+	switch ext := filepath.Ext(path); ext {
+	case ".fasta", ".fa", ".fna":
+		seqs, err = readFASTASequences(f)
+	case ".tsv":
+		seqs, err = readTSVSequences(f)
+	case ".jsonl":
+		seqs, err = readJSONLSequences(f)
+	default:
+		return nil, fmt.Errorf("%s: cannot read sites from %q files (want .fasta, .tsv or .jsonl)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return seqs, nil
+}
+
+// readFASTASequences splits each record at the upstream=, site= and
+// downstream= lengths of its header.
+func readFASTASequences(r io.Reader) ([]SiteSequence, error) {
+	fr := fasta.NewReader(r)
+	var seqs []SiteSequence
+	for {
+		rec, err := fr.Read()
+		if err == io.EOF {
+			return seqs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lengths := map[string]int{}
+		for _, field := range strings.Fields(rec.Desc) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.Atoi(value); err == nil {
+				lengths[key] = n
+			}
+		}
+		up, site, down := lengths["upstream"], lengths["site"], lengths["downstream"]
+		if up+site+down != len(rec.Seq) {
+			return nil, fmt.Errorf("record %s: upstream=%d site=%d downstream=%d do not add up to its %d bases",
+				rec.ID, up, site, down, len(rec.Seq))
+		}
+		seqs = append(seqs, SiteSequence{
+			ID:         rec.ID,
+			Upstream:   rec.Seq[:up],
+			Site:       rec.Seq[up : up+site],
+			Downstream: rec.Seq[up+site:],
+		})
+	}
+}
+
+func readTSVSequences(r io.Reader) ([]SiteSequence, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 1<<16), 1<<26)
+	var (
+		seqs  []SiteSequence
+		index map[string]int
+	)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		cells := strings.Split(strings.TrimRight(sc.Text(), "\r"), "\t")
+		if index == nil {
+			index = map[string]int{}
+			for i, c := range cells {
+				index[c] = i
+			}
+			for _, c := range []string{"mirna_id", "target_id", "start", "end", "strand", "site", "upstream", "downstream"} {
+				if _, ok := index[c]; !ok {
+					return nil, fmt.Errorf("header has no %s column", c)
+				}
+			}
+			continue
+		}
+		if len(cells) < len(index) {
+			return nil, fmt.Errorf("line %d: %d columns, header has %d", lineNo, len(cells), len(index))
+		}
+		get := func(c string) string { return cells[index[c]] }
+		seqs = append(seqs, SiteSequence{
+			ID:         fmt.Sprintf("%s|%s:%s-%s(%s)", get("mirna_id"), get("target_id"), get("start"), get("end"), get("strand")),
+			Upstream:   get("upstream"),
+			Site:       get("site"),
+			Downstream: get("downstream"),
+		})
+	}
+	return seqs, sc.Err()
+}
+
+func readJSONLSequences(r io.Reader) ([]SiteSequence, error) {
+	dec := json.NewDecoder(r)
+	var seqs []SiteSequence
+	for {
+		var s jsonSite
+		err := dec.Decode(&s)
+		if err == io.EOF {
+			return seqs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(seqs)+1, err)
+		}
+		seqs = append(seqs, SiteSequence{
+			ID:         fmt.Sprintf("%s|%s:%d-%d(%s)", s.MiRNAID, s.TargetID, s.Start, s.End, s.Strand),
+			Upstream:   s.Upstream,
+			Site:       s.Site,
+			Downstream: s.Downstream,
+		})
+	}
+}
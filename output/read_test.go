@@ -0,0 +1,66 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-microRNAs/predict"
+)
+
+func TestReadSequences(t *testing.T) {
+	sites := []predict.TargetSite{
+		{
+			Tool: predict.ToolTargetFinder, MiRNAID: "miR399a", TargetID: "AT2G33770.1",
+			Start: 10, End: 31, Strand: "+", Score: 1.5, Cleavage: -1,
+			Upstream: "ACGTACGTAC", Sequence: strings.Repeat("TTGCA", 14)[:21], Downstream: "GGGCC",
+		},
+		{
+			Tool: predict.ToolTargetFinder, MiRNAID: "miR156a", TargetID: "chr1:101-200_-",
+			Start: 0, End: 4, Strand: "-", Cleavage: -1,
+			Sequence: "ACGT", Downstream: strings.Repeat("A", 70),
+		},
+	}
+	want := []SiteSequence{
+		{ID: "miR399a|AT2G33770.1:11-31(+)", Upstream: sites[0].Upstream, Site: sites[0].Sequence, Downstream: sites[0].Downstream},
+		{ID: "miR156a|chr1:101-200_-:1-4(-)", Site: "ACGT", Downstream: sites[1].Downstream},
+	}
+	for _, format := range []string{"fasta", "tsv", "jsonl"} {
+		t.Run(format, func(t *testing.T) {
+			ext, err := Extension(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "sites"+ext)
+			w, err := Create(format, path, Options{Coords: predict.OneBased})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range sites {
+				if err := w.Write(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadSequences(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+
+	bed := filepath.Join(t.TempDir(), "sites.bed")
+	if err := os.WriteFile(bed, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSequences(bed); err == nil || !strings.Contains(err.Error(), `".bed" files`) {
+		t.Errorf("reading bed gives %v", err)
+	}
+}
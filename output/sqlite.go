@@ -0,0 +1,24 @@
+package output
+
+import (
+	"github.com/go-microRNAs/predict"
+	"github.com/go-microRNAs/sitedb"
+)
+
+// sqliteWriter adds the sites to a sitedb database; see that package
+// for the tables.
+type sqliteWriter struct {
+	db *sitedb.Writer
+}
+
+func openSQLite(path string, opt Options) (Writer, error) {
+	db, err := sitedb.Create(path, opt.Coords)
+	if err != nil {
+		return nil, err
+	}
+	return sqliteWriter{db}, nil
+}
+
+func (sw sqliteWriter) Write(s predict.TargetSite) error { return sw.db.Add(s) }
+
+func (sw sqliteWriter) Close() error { return sw.db.Close() }
@@ -0,0 +1,135 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-microRNAs/predict"
+)
+
+// fastaWidth is the line width sequences are wrapped at.
+const fastaWidth = 60
+
+// FASTAWriter writes one record per site. The header names the miRNA and
+// the site on its target and records the tool, score and flank lengths,
+// so the upstream, site and downstream parts of the sequence can be
+// split again. Sites on genome region targets also carry genome=:
+//
+//	>miR399a|AT2G33770.1:10-37(+) tool=TargetFinder score=1.5 upstream=10 site=27 downstream=10
+type FASTAWriter struct {
+	w   io.Writer
+	opt Options
+}
+
+// NewFASTA returns a FASTAWriter writing to w.
+func NewFASTA(w io.Writer, opt Options) *FASTAWriter {
+	return &FASTAWriter{w: w, opt: opt}
+}
+
+// Write writes the site with its flanks as a single record.
+func (fw *FASTAWriter) Write(s predict.TargetSite) error {
+	start, end := fw.opt.Coords.FromInternal(s.Start, s.End)
+	genome := ""
+	if s.Genome != nil {
+		gStart, gEnd := fw.opt.Coords.FromInternal(s.Genome.Start, s.Genome.End)
+		genome = fmt.Sprintf(" genome=%s:%d-%d(%s)", s.Genome.Chrom, gStart, gEnd, s.Genome.Strand)
+	}
+	_, err := fmt.Fprintf(fw.w, ">%s|%s:%d-%d(%s) tool=%s score=%s upstream=%d site=%d downstream=%d%s\n",
+		s.MiRNAID, s.TargetID, start, end, s.Strand, s.Tool, formatFloat(s.Score),
+		len(s.Upstream), len(s.Sequence), len(s.Downstream), genome)
+	if err != nil {
+		return err
+	}
+	seq := s.Upstream + s.Sequence + s.Downstream
+	for i := 0; i < len(seq); i += fastaWidth {
+		if _, err := io.WriteString(fw.w, seq[i:min(i+fastaWidth, len(seq))]+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op; the caller owns the underlying writer.
+func (fw *FASTAWriter) Close() error {
+	return nil
+}
+
+// Columns are the TSV header names in output order.
+var Columns = []string{
+	"tool", "mirna_id", "mirna_seq", "target_id", "start", "end", "strand", "score",
+	"mirna_aligned", "target_aligned", "match", "site", "upstream", "downstream",
+	"upstream_pad", "downstream_pad",
+	"chrom", "genome_start", "genome_end", "genome_strand",
+}
+
+// TSVWriter writes a header row followed by one row per site.
+type TSVWriter struct {
+	w      io.Writer
+	opt    Options
+	header bool
+}
+
+// NewTSV returns a TSVWriter writing to w.
+func NewTSV(w io.Writer, opt Options) *TSVWriter {
+	return &TSVWriter{w: w, opt: opt}
+}
+
+// Write writes the site as one row, preceded by the header on first use.
+func (tw *TSVWriter) Write(s predict.TargetSite) error {
+	if !tw.header {
+		if err := tw.writeRow(Columns); err != nil {
+			return err
+		}
+		tw.header = true
+	}
+	return tw.writeRow(row(s, tw.opt))
+}
+
+// Close writes the header if no site was written, so an empty result is
+// still a valid table.
+func (tw *TSVWriter) Close() error {
+	if !tw.header {
+		tw.header = true
+		return tw.writeRow(Columns)
+	}
+	return nil
+}
+
+func (tw *TSVWriter) writeRow(cells []string) error {
+	_, err := io.WriteString(tw.w, strings.Join(cells, "\t")+"\n")
+	return err
+}
+
+// row returns the site's values in Columns order.
+func row(s predict.TargetSite, opt Options) []string {
+	start, end := opt.Coords.FromInternal(s.Start, s.End)
+	cells := []string{
+		s.Tool,
+		s.MiRNAID,
+		s.MiRNASeq,
+		s.TargetID,
+		strconv.Itoa(start),
+		strconv.Itoa(end),
+		s.Strand,
+		formatFloat(s.Score),
+		s.MiRNAAligned,
+		s.TargetAligned,
+		s.Match,
+		s.Sequence,
+		s.Upstream,
+		s.Downstream,
+		strconv.Itoa(s.UpstreamPad),
+		strconv.Itoa(s.DownstreamPad),
+	}
+	if s.Genome == nil {
+		return append(cells, "", "", "", "")
+	}
+	gStart, gEnd := opt.Coords.FromInternal(s.Genome.Start, s.Genome.End)
+	return append(cells, s.Genome.Chrom, strconv.Itoa(gStart), strconv.Itoa(gEnd), s.Genome.Strand)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
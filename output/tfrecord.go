@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/go-microRNAs/encode"
+	"github.com/go-microRNAs/predict"
+	"github.com/go-microRNAs/tfrecord"
+)
+
+// TFRecordWriter writes each site as a tf.train.Example with the
+// features
+//
+//	site_tokens, upstream_tokens, downstream_tokens   int64 lists of encode tokens
+//	label, start, end                                 int64
+//	score                                             float
+//	feature/<name>                                    float, one per site feature
+//	tool, mirna_id, target_id, strand                 bytes
+//
+// Sites cut to windows also carry window_tokens, attention_mask and
+// segment_ids as int64 lists of the window length, and window_offset.
+//
+// With Options.Shards above one, sites are dealt round robin over files
+// named <path>-00000-of-0000N.
+type TFRecordWriter struct {
+	opt    Options
+	files  []*os.File
+	bufs   []*bufio.Writer
+	shards []*tfrecord.Writer
+	next   int
+}
+
+// ShardPath returns the name of shard i of n written for path.
+func ShardPath(path string, i, n int) string {
+	if n <= 1 {
+		return path
+	}
+	return fmt.Sprintf("%s-%05d-of-%05d", path, i, n)
+}
+
+func openTFRecord(path string, opt Options) (Writer, error) {
+	n := max(opt.Shards, 1)
+	tw := &TFRecordWriter{opt: opt}
+	for i := 0; i < n; i++ {
+		f, err := os.Create(ShardPath(path, i, n))
+		if err != nil {
+			tw.Close()
+			return nil, err
+		}
+		b := bufio.NewWriter(f)
+		tw.files = append(tw.files, f)
+		tw.bufs = append(tw.bufs, b)
+		tw.shards = append(tw.shards, tfrecord.NewWriter(b))
+	}
+	return tw, nil
+}
+
+func ints(v []uint8) []int64 {
+	out := make([]int64, len(v))
+	for i, x := range v {
+		out[i] = int64(x)
+	}
+	return out
+}
+
+func tokens(seq string) tfrecord.Feature {
+	v := make([]int64, len(seq))
+	for i := 0; i < len(seq); i++ {
+		v[i] = int64(encode.Token(seq[i]))
+	}
+	return tfrecord.Int64s(v...)
+}
+
+// Write encodes the site and appends it to the next shard.
+func (tw *TFRecordWriter) Write(s predict.TargetSite) error {
+	start, end := tw.opt.Coords.FromInternal(s.Start, s.End)
+	ex := tfrecord.Example{
+		"site_tokens":       tokens(s.Sequence),
+		"upstream_tokens":   tokens(s.Upstream),
+		"downstream_tokens": tokens(s.Downstream),
+		"label":             tfrecord.Int64s(int64(tw.opt.Label)),
+		"start":             tfrecord.Int64s(int64(start)),
+		"end":               tfrecord.Int64s(int64(end)),
+		"score":             tfrecord.Floats(float32(s.Score)),
+		"tool":              tfrecord.Strings(s.Tool),
+		"mirna_id":          tfrecord.Strings(s.MiRNAID),
+		"target_id":         tfrecord.Strings(s.TargetID),
+		"strand":            tfrecord.Strings(s.Strand),
+	}
+	for name, v := range s.Features {
+		ex["feature/"+name] = tfrecord.Floats(float32(v))
+	}
+	if w := s.Window; w != nil {
+		ex["window_tokens"] = tfrecord.Int64s(ints(encode.Integer.AppendMasked(nil, w.Sequence, w.Mask, len(w.Sequence)))...)
+		ex["attention_mask"] = tfrecord.Int64s(ints(w.Mask)...)
+		ex["segment_ids"] = tfrecord.Int64s(ints(w.Segments)...)
+		ex["window_offset"] = tfrecord.Int64s(int64(w.Offset))
+	}
+	err := tw.shards[tw.next].Write(ex.Marshal())
+	tw.next = (tw.next + 1) % len(tw.shards)
+	return err
+}
+
+// Close flushes and closes every shard.
+func (tw *TFRecordWriter) Close() error {
+	var err error
+	for i, f := range tw.files {
+		if ferr := tw.bufs[i].Flush(); err == nil {
+			err = ferr
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package output
+
+import "github.com/go-microRNAs/predict"
+
+// windowed reports whether the sites were cut to a fixed length window
+// during extraction; either all of them were or none.
+func windowed(sites []predict.TargetSite) bool {
+	return len(sites) > 0 && sites[0].Window != nil
+}
+
+// tensorInput returns what the tensor formats encode for a site: its
+// window with the window's mask, or upstream+site+downstream and a nil
+// mask.
+func tensorInput(s predict.TargetSite) (string, []uint8) {
+	if s.Window != nil {
+		return s.Window.Sequence, s.Window.Mask
+	}
+	return s.Upstream + s.Sequence + s.Downstream, nil
+}
+
+// tensorLength is the length L every site is encoded at: the window
+// length, or else the longest upstream+site+downstream, and at least
+// least.
+func tensorLength(sites []predict.TargetSite, least int) int {
+	length := least
+	for _, s := range sites {
+		seq, _ := tensorInput(s)
+		length = max(length, len(seq))
+	}
+	return length
+}
+
+// windowColumns returns the masks and segment IDs of windowed sites
+// flattened to N×L.
+func windowColumns(sites []predict.TargetSite) (mask, segments []uint8, offsets []int32) {
+	for _, s := range sites {
+		mask = append(mask, s.Window.Mask...)
+		segments = append(segments, s.Window.Segments...)
+		offsets = append(offsets, int32(s.Window.Offset))
+	}
+	return mask, segments, offsets
+}
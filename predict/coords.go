@@ -0,0 +1,69 @@
+package predict
+
+import "fmt"
+
+// Convention is the way positions on a target are numbered. TargetSite
+// always holds ZeroBased coordinates; each tool's records declare their
+// native convention through a Coords method and are converted by Site.
+type Convention int
+
+const (
+	// ZeroBased is 0-based and half-open, [start, end), as Go slices.
+	ZeroBased Convention = iota
+	// OneBased is 1-based and inclusive, [start, end].
+	OneBased
+)
+
+func (c Convention) String() string {
+	if c == OneBased {
+		return "1-based"
+	}
+	return "0-based"
+}
+
+// ParseConvention accepts "0", "1", "0-based" or "1-based".
+func ParseConvention(s string) (Convention, error) {
+	switch s {
+	case "0", "0-based":
+		return ZeroBased, nil
+	case "1", "1-based":
+		return OneBased, nil
+	}
+	return ZeroBased, fmt.Errorf("unknown coordinate convention %q (want 0 or 1)", s)
+}
+
+// ToInternal converts start and end in convention c to 0-based half-open.
+func (c Convention) ToInternal(start, end int) (int, int) {
+	if c == OneBased {
+		return start - 1, end
+	}
+	return start, end
+}
+
+// FromInternal converts 0-based half-open start and end to convention c.
+func (c Convention) FromInternal(start, end int) (int, int) {
+	if c == OneBased {
+		return start + 1, end
+	}
+	return start, end
+}
+
+// Coords reports that psRNATarget numbers Target_start and Target_end
+// 1-based inclusive.
+func (PsRNATarget) Coords() Convention { return OneBased }
+
+// Coords reports that TAPIR numbers start 1-based; End is inclusive.
+func (Tapir) Coords() Convention { return OneBased }
+
+// Coords reports that TarHunter numbers Start_pos and Slice_pos 1-based;
+// End is inclusive.
+func (TarHunter) Coords() Convention { return OneBased }
+
+// Coords reports that TargetFinder ranges are 1-based inclusive.
+func (TargetFinder) Coords() Convention { return OneBased }
+
+// Coords reports that psRobot Sbjct coordinates are 1-based inclusive.
+func (PsRobot) Coords() Convention { return OneBased }
+
+// Coords reports that psRNA map start and stop are 1-based inclusive.
+func (PsRNAMap) Coords() Convention { return OneBased }
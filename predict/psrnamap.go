@@ -0,0 +1,83 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PsRNAMap is one alignment of a small RNA read from a psRNA map file:
+// read ID, reference, strand, start, stop and read sequence.
+type PsRNAMap struct {
+	ReadID string
+	Ref    string
+	Strand string
+	Start  int
+	Stop   int
+	Read   string
+	Origin Origin
+}
+
+// ReadPsRNAMap parses a psRNA map alignment file.
+func ReadPsRNAMap(r io.Reader) ([]PsRNAMap, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	hits := []PsRNAMap{}
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 6 {
+			return nil, fmt.Errorf("psRNA map line %d: expected 6 columns, got %d", lineNo, len(f))
+		}
+		start, err := strconv.Atoi(f[3])
+		if err != nil {
+			return nil, fmt.Errorf("psRNA map line %d: start: %w", lineNo, err)
+		}
+		stop, err := strconv.Atoi(f[4])
+		if err != nil {
+			return nil, fmt.Errorf("psRNA map line %d: stop: %w", lineNo, err)
+		}
+		strand := normalizeStrand(f[2])
+		if strand == "." {
+			return nil, fmt.Errorf("psRNA map line %d: unknown strand %q", lineNo, f[2])
+		}
+		hits = append(hits, PsRNAMap{
+			ReadID: f[0],
+			Ref:    f[1],
+			Strand: strand,
+			Start:  start,
+			Stop:   stop,
+			Read:   f[5],
+			Origin: Origin{Line: lineNo, Text: line},
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// ReadPsRNAMapFile parses the psRNA map file at path.
+func ReadPsRNAMapFile(path string) ([]PsRNAMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hits, err := ReadPsRNAMap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range hits {
+		hits[i].Origin.File = path
+	}
+	return hits, nil
+}
@@ -0,0 +1,73 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadPsRNAMap(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/psRNA-map.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(data)
+	minus := strings.Replace(sample, "SrID003\tref01\t+", "SrID003\tref01\t-", 1)
+
+	tests := []struct {
+		name   string
+		in     string
+		strand string
+		err    string
+	}{
+		{"sample", sample, "+", ""},
+		{"minus strand", minus, "-", ""},
+		{"numeric strand", strings.Replace(sample, "ref01\t+", "ref01\t-1", 1), "-", ""},
+		{"space separated", strings.ReplaceAll(sample, "\t", "  "), "+", ""},
+		{"unknown strand", strings.Replace(sample, "ref01\t+", "ref01\t?", 1), "", `unknown strand "?"`},
+		{"short line", "SrID003\tref01\t+\t10\n", "", "expected 6 columns, got 4"},
+		{"bad stop", strings.Replace(sample, "\t21\t", "\tx\t", 1), "", "line 1: stop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := ReadPsRNAMap(strings.NewReader(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != 2 {
+				t.Fatalf("got %d hits, want 2", len(hits))
+			}
+			h := hits[0]
+			if h.ReadID != "SrID003" || h.Ref != "ref01" || h.Strand != tt.strand || h.Start != 10 || h.Stop != 21 || h.Read != "TTGACAGAAGAGAGTGAGCAC" {
+				t.Errorf("got %+v", h)
+			}
+			if hits[1].Strand != "+" || hits[1].Origin.Line != 2 {
+				t.Errorf("got second hit on %s from line %d", hits[1].Strand, hits[1].Origin.Line)
+			}
+		})
+	}
+}
+
+func TestPsRNAMapSite(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/psRNA-map.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits, err := ReadPsRNAMap(strings.NewReader(strings.Replace(string(data), "ref01\t+", "ref01\t-", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := hits[0].Site()
+	if s.Start != 9 || s.End != 21 || s.Strand != "-" || s.Cleavage != -1 {
+		t.Errorf("got %d-%d on %s, cleavage %d", s.Start, s.End, s.Strand, s.Cleavage)
+	}
+	if s.MiRNASeq != "TTGACAGAAGAGAGTGAGCAC" || s.Raw["strand"] != "-" {
+		t.Errorf("got %s, raw %v", s.MiRNASeq, s.Raw)
+	}
+}
@@ -0,0 +1,124 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PsRNATarget is one row of a psRNATarget tab-delimited download.
+type PsRNATarget struct {
+	MiRNA         string
+	Target        string
+	Expectation   float64
+	UPE           float64
+	MiRNAStart    int
+	MiRNAEnd      int
+	TargetStart   int
+	TargetEnd     int
+	MiRNAAligned  string
+	TargetAligned string
+	Inhibition    string
+	TargetDesc    string
+	Multiplicity  int
+	Origin        Origin
+	// Fields holds every column of the row keyed by its header name,
+	// including any the server adds that are not mapped above.
+	Fields map[string]string
+}
+
+// Features returns the numeric columns of the row for model input.
+// UPE is -1 when psRNATarget did not compute it.
+func (p PsRNATarget) Features() map[string]float64 {
+	cleavage := 0.0
+	if strings.EqualFold(p.Inhibition, "Cleavage") {
+		cleavage = 1
+	}
+	return map[string]float64{
+		"expectation":  p.Expectation,
+		"upe":          p.UPE,
+		"multiplicity": float64(p.Multiplicity),
+		"cleavage":     cleavage,
+	}
+}
+
+// ReadPsRNATarget parses psRNATarget output. Lines starting with '#',
+// such as the spreadsheet import note, are skipped and the first other
+// line must be the header row.
+func ReadPsRNATarget(r io.Reader) ([]PsRNATarget, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var (
+		header []string
+		cols   columns
+		lineNo int
+	)
+	rows := []PsRNATarget{}
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cells := strings.Split(line, "\t")
+		if header == nil {
+			header = cells
+			cols = newColumns(header)
+			if err := cols.require("mirna_acc", "target_acc", "target_start", "target_end"); err != nil {
+				return nil, fmt.Errorf("psRNATarget line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		if len(cells) < len(header) {
+			return nil, fmt.Errorf("psRNATarget line %d: expected %d columns, got %d", lineNo, len(header), len(cells))
+		}
+		rw := &row{cells: cells, header: header, cols: cols}
+		rec := PsRNATarget{
+			MiRNA:         rw.str("mirna_acc"),
+			Target:        rw.str("target_acc"),
+			Expectation:   rw.float("expectation"),
+			UPE:           rw.float("upe"),
+			MiRNAStart:    rw.int("mirna_start"),
+			MiRNAEnd:      rw.int("mirna_end"),
+			TargetStart:   rw.int("target_start"),
+			TargetEnd:     rw.int("target_end"),
+			MiRNAAligned:  rw.str("mirna_aligned_fragment"),
+			TargetAligned: rw.str("target_aligned_fragment"),
+			Inhibition:    rw.str("inhibition"),
+			TargetDesc:    rw.str("target_desc"),
+			Multiplicity:  rw.int("multiplicity"),
+			Fields:        rw.fields(),
+			Origin:        Origin{Line: lineNo, Text: line},
+		}
+		if rw.err != nil {
+			return nil, fmt.Errorf("psRNATarget line %d: %w", lineNo, rw.err)
+		}
+		rows = append(rows, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("psRNATarget: no header row found")
+	}
+	return rows, nil
+}
+
+// ReadPsRNATargetFile parses the psRNATarget output at path.
+func ReadPsRNATargetFile(path string) ([]PsRNATarget, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rows, err := ReadPsRNATarget(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range rows {
+		rows[i].Origin.File = path
+	}
+	return rows, nil
+}
@@ -0,0 +1,89 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadPsRNATarget(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/targetanalyzer.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(data)
+	header := strings.SplitN(sample, "\n", 3)[1]
+
+	tests := []struct {
+		name  string
+		in    string
+		rows  int
+		first int
+		err   string
+	}{
+		{"excel note and header", sample, 2, 3, ""},
+		{"no excel note", strings.SplitN(sample, "\n", 2)[1], 2, 2, ""},
+		{"crlf", strings.ReplaceAll(sample, "\n", "\r\n"), 2, 3, ""},
+		{"header only", header + "\n", 0, 0, ""},
+		{"no header", "#comment only\n", 0, 0, "no header row"},
+		{"missing column", "miRNA_Acc.\tTarget_start\tTarget_end\n", 0, 0, `missing "target_acc" column`},
+		{"short row", header + "\nath-miR5658\tchr5\n", 0, 0, "expected 13 columns, got 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadPsRNATarget(strings.NewReader(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != tt.rows {
+				t.Fatalf("got %d rows, want %d", len(rows), tt.rows)
+			}
+			if tt.rows == 0 {
+				return
+			}
+			r := rows[0]
+			if r.MiRNA != "ath-miR5658" || r.Target != "chr5:6013917-6014399_" || r.Expectation != 2.5 || r.UPE != -1 {
+				t.Errorf("got %s on %s, expectation %g, UPE %g", r.MiRNA, r.Target, r.Expectation, r.UPE)
+			}
+			if r.TargetStart != 10 || r.TargetEnd != 31 || r.Multiplicity != 1 || r.Inhibition != "Cleavage" {
+				t.Errorf("got %d-%d, multiplicity %d, inhibition %q", r.TargetStart, r.TargetEnd, r.Multiplicity, r.Inhibition)
+			}
+			if r.Fields["UPE$"] != "-1.0" {
+				t.Errorf("got raw UPE %q", r.Fields["UPE$"])
+			}
+			if r.Origin.Line != tt.first {
+				t.Errorf("got origin line %d, want %d", r.Origin.Line, tt.first)
+			}
+		})
+	}
+}
+
+func TestPsRNATargetSite(t *testing.T) {
+	rows, err := ReadPsRNATargetFile("../sample-files/targetanalyzer.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := rows[1].Site()
+	if s.Start != 4 || s.End != 27 || s.Strand != "+" {
+		t.Errorf("got %d-%d %s, want 4-27 +", s.Start, s.End, s.Strand)
+	}
+	if s.MiRNASeq != "TGAGAGAAGTGAGATGAAATC" {
+		t.Errorf("got miRNA %s", s.MiRNASeq)
+	}
+	// The miRNA fragment is reversed to pair with the target 5'->3'.
+	if s.MiRNAAligned != "CTAAAGTAGAGTGAAGAGAGT" || s.TargetAligned != "AATATGATCTCACTTCTCTCT" {
+		t.Errorf("got alignment\n%s\n%s", s.MiRNAAligned, s.TargetAligned)
+	}
+	if s.Genome == nil || s.Genome.Chrom != "chr5" || s.Genome.Start != 939075 {
+		t.Errorf("got genome %+v", s.Genome)
+	}
+	if s.Origin.File != "../sample-files/targetanalyzer.txt" || s.Origin.Line != 4 {
+		t.Errorf("got origin %s:%d", s.Origin.File, s.Origin.Line)
+	}
+}
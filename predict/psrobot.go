@@ -0,0 +1,170 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PsRobot is one hit block of psRobot_tar output.
+type PsRobot struct {
+	SmRNA        string
+	Score        float64
+	Target       string
+	QueryStart   int
+	QueryEnd     int
+	SubjectStart int
+	SubjectEnd   int
+	QueryAln     string
+	Match        string
+	SubjectAln   string
+	Origin       Origin
+	// Fields holds any further tab separated columns of the header line.
+	Fields map[string]string
+}
+
+// Features returns the numeric fields of the hit for model input.
+func (p PsRobot) Features() map[string]float64 {
+	return map[string]float64{
+		"score":      p.Score,
+		"mismatches": float64(strings.Count(p.Match, "*")),
+	}
+}
+
+// alignmentLine splits "Query:   1 TGACAG 20" into its coordinates and
+// sequence, returning the column the sequence starts at.
+func alignmentLine(line string) (start, end int, seq string, col int, err error) {
+	f := strings.Fields(line)
+	if len(f) != 4 {
+		return 0, 0, "", 0, fmt.Errorf("expected label, start, sequence and end in %q", line)
+	}
+	if start, err = strconv.Atoi(f[1]); err != nil {
+		return
+	}
+	if end, err = strconv.Atoi(f[3]); err != nil {
+		return
+	}
+	seq = f[2]
+	col = strings.Index(line[len(f[0]):], seq) + len(f[0])
+	return
+}
+
+// ReadPsRobot parses psRobot_tar output into one record per hit. Each
+// hit is a ">smRNA<TAB>Score: x<TAB>target" header followed by the
+// Query, match and Sbjct lines. SubjectStart is always the lower end of
+// the Sbjct range.
+func ReadPsRobot(r io.Reader) ([]PsRobot, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	hits := []PsRobot{}
+	var (
+		cur      *PsRobot
+		lineNo   int
+		queryCol = -1
+		block    []string
+	)
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		if cur.SubjectAln == "" {
+			return fmt.Errorf("psRobot: hit %s on %s before line %d has no Sbjct line", cur.SmRNA, cur.Target, lineNo)
+		}
+		cur.Origin.Text = strings.Join(block, "\n")
+		hits = append(hits, *cur)
+		cur = nil
+		return nil
+	}
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if cur != nil && cur.SubjectAln == "" {
+			block = append(block, line)
+		}
+		switch {
+		case strings.HasPrefix(line, ">"):
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			cols := strings.Split(strings.TrimPrefix(line, ">"), "\t")
+			if len(cols) < 3 {
+				return nil, fmt.Errorf("psRobot line %d: expected smRNA, score and target in header", lineNo)
+			}
+			label, value, _ := strings.Cut(cols[1], ":")
+			if strings.TrimSpace(label) != "Score" {
+				return nil, fmt.Errorf("psRobot line %d: expected Score in second column, got %q", lineNo, cols[1])
+			}
+			score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("psRobot line %d: score: %w", lineNo, err)
+			}
+			cur = &PsRobot{
+				SmRNA:  strings.TrimSpace(cols[0]),
+				Score:  score,
+				Target: strings.TrimSpace(cols[2]),
+				Fields: map[string]string{},
+				Origin: Origin{Line: lineNo},
+			}
+			block = append(block[:0], line)
+			for i, extra := range cols[3:] {
+				cur.Fields["column"+strconv.Itoa(i+4)] = strings.TrimSpace(extra)
+			}
+		case strings.HasPrefix(line, "Query"):
+			if cur == nil {
+				return nil, fmt.Errorf("psRobot line %d: Query line outside a hit", lineNo)
+			}
+			var err error
+			cur.QueryStart, cur.QueryEnd, cur.QueryAln, queryCol, err = alignmentLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("psRobot line %d: %w", lineNo, err)
+			}
+		case strings.HasPrefix(line, "Sbjct"):
+			if cur == nil {
+				return nil, fmt.Errorf("psRobot line %d: Sbjct line outside a hit", lineNo)
+			}
+			var err error
+			cur.SubjectStart, cur.SubjectEnd, cur.SubjectAln, _, err = alignmentLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("psRobot line %d: %w", lineNo, err)
+			}
+			// The target runs 3'->5' along the Sbjct line, so its
+			// range may be printed high to low.
+			cur.SubjectStart, cur.SubjectEnd = min(cur.SubjectStart, cur.SubjectEnd), max(cur.SubjectStart, cur.SubjectEnd)
+			queryCol = -1
+		case queryCol >= 0 && cur != nil && cur.Match == "":
+			// The match line sits between Query and Sbjct and is
+			// aligned under the query sequence.
+			if queryCol < len(line) {
+				end := min(len(line), queryCol+len(cur.QueryAln))
+				cur.Match = line[queryCol:end]
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// ReadPsRobotFile parses the psRobot_tar output at path.
+func ReadPsRobotFile(path string) ([]PsRobot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hits, err := ReadPsRobot(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range hits {
+		hits[i].Origin.File = path
+	}
+	return hits, nil
+}
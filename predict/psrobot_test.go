@@ -0,0 +1,91 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadPsRobot(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/psRobot-tar.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(data)
+	descending := strings.Replace(sample, "23 ACTGTCTTCTCTCTCTCGTG 43", "43 ACTGTCTTCTCTCTCTCGTG 23", 1)
+	extra := strings.Replace(sample, "tar02\n", "tar02\tAT1G01010.1\n", 1)
+
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"sample", sample, ""},
+		{"descending sbjct range", descending, ""},
+		{"extra header column", extra, ""},
+		{"crlf", strings.ReplaceAll(sample, "\n", "\r\n"), ""},
+		{"no sbjct", strings.Split(sample, "Sbjct")[0], "has no Sbjct line"},
+		{"query outside hit", "Query:  1 TGACAG 6\n", "Query line outside a hit"},
+		{"bad score", strings.Replace(sample, "Score: 1.0", "Score: high", 1), "line 1: score"},
+		{"no score", strings.Replace(sample, "Score: 1.0", "1.0", 1), "expected Score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := ReadPsRobot(strings.NewReader(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != 2 {
+				t.Fatalf("got %d hits, want 2", len(hits))
+			}
+			h := hits[0]
+			if h.SmRNA != "smRNA01" || h.Score != 1 || h.Target != "tar02" {
+				t.Errorf("got %s on %s scoring %g", h.SmRNA, h.Target, h.Score)
+			}
+			if h.QueryStart != 1 || h.QueryEnd != 20 || h.SubjectStart != 23 || h.SubjectEnd != 43 {
+				t.Errorf("got query %d-%d, subject %d-%d", h.QueryStart, h.QueryEnd, h.SubjectStart, h.SubjectEnd)
+			}
+			if h.QueryAln != "TGACAGAAGAGAGTGAGCAC" || h.Match != "|||||||||||||*||||||" || h.SubjectAln != "ACTGTCTTCTCTCTCTCGTG" {
+				t.Errorf("got alignment\n%q\n%q\n%q", h.QueryAln, h.Match, h.SubjectAln)
+			}
+			if hits[1].Match != "|||||||||||||||||*||:" {
+				t.Errorf("got second match line %q", hits[1].Match)
+			}
+			if h.Origin.Line != 1 || strings.Count(h.Origin.Text, "\n") != 4 {
+				t.Errorf("got origin line %d, text %q", h.Origin.Line, h.Origin.Text)
+			}
+		})
+	}
+	hits, err := ReadPsRobot(strings.NewReader(extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hits[0].Fields["column4"]; got != "AT1G01010.1" {
+		t.Errorf("got extra column %q", got)
+	}
+}
+
+func TestPsRobotSite(t *testing.T) {
+	hits, err := ReadPsRobotFile("../sample-files/psRobot-tar.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := hits[0].Site()
+	if s.Start != 22 || s.End != 43 || s.MiRNASeq != "TGACAGAAGAGAGTGAGCAC" {
+		t.Errorf("got %s at %d-%d", s.MiRNASeq, s.Start, s.End)
+	}
+	// Sbjct is the complement of Query base by base, so both are
+	// reversed to read the target 5'->3'.
+	if s.MiRNAAligned != "CACGAGTGAGAGAAGACAGT" || s.Match != "||||||*|||||||||||||" || s.TargetAligned != "GTGCTCTCTCTCTTCTGTCA" {
+		t.Errorf("got alignment\n%s\n%s\n%s", s.MiRNAAligned, s.Match, s.TargetAligned)
+	}
+	if s.Features["mismatches"] != 1 {
+		t.Errorf("got %g mismatches", s.Features["mismatches"])
+	}
+}
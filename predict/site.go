@@ -0,0 +1,276 @@
+package predict
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-microRNAs/region"
+	"github.com/go-microRNAs/sequtil"
+	"github.com/go-microRNAs/window"
+)
+
+// Names of the prediction tools as recorded in TargetSite.Tool.
+const (
+	ToolPsRNATarget  = "psRNATarget"
+	ToolTapir        = "TAPIR"
+	ToolTarHunter    = "TarHunter"
+	ToolTargetFinder = "TargetFinder"
+	ToolPsRobot      = "psRobot"
+	ToolPsRNAMap     = "psRNAmap"
+)
+
+// TargetSite is a predicted miRNA target site in the form every tool's
+// records are converted into. Start and End locate the site on TargetID
+// 0-based and half-open whatever the tool's own numbering, and Strand is
+// the strand of TargetID the site lies on. Cleavage is the position the
+// target is cut before, or -1 when the tool gives none. Sequences and
+// alignments use the DNA alphabet; Sequence, Upstream and Downstream are
+// filled in by extraction and read 5' to 3' on Strand.
+//
+// The alignment strings are paired position by position: TargetAligned
+// reads the target 5' to 3', MiRNAAligned reads the miRNA 3' to 5' so
+// each base sits against the target base it pairs with, and Match marks
+// the pair at each column. MiRNASeq reads 5' to 3'.
+type TargetSite struct {
+	Tool          string
+	MiRNAID       string
+	MiRNASeq      string
+	TargetID      string
+	Start         int
+	End           int
+	Strand        string
+	Cleavage      int
+	Score         float64
+	MiRNAAligned  string
+	TargetAligned string
+	Match         string
+	Sequence      string
+	Upstream      string
+	Downstream    string
+	// Genome is the site on the genome when TargetID names a genome
+	// region such as chr5:6013917-6014399_ that holds it, and nil
+	// otherwise.
+	Genome *region.Interval
+	// UpstreamPad and DownstreamPad count flank bases that lay beyond
+	// the ends of the target.
+	UpstreamPad   int
+	DownstreamPad int
+	// Features holds the tool specific numeric columns and Raw every
+	// field of the original record as text.
+	Features map[string]float64
+	Raw      map[string]string
+	// Origin points back at the record the site was read from.
+	Origin Origin
+	// Window is set when extraction cuts the site to a fixed length.
+	Window *window.Window
+}
+
+// Scored reports whether the tool gave the site a score. psRNAmap only
+// aligns reads, so its sites leave Score at 0.
+func (s TargetSite) Scored() bool {
+	return s.Tool != ToolPsRNAMap && !math.IsNaN(s.Score)
+}
+
+// reverse returns s read backwards, turning a 3'->5' alignment string
+// into 5'->3'.
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// normalize finishes a converted site: the U of RNA alignments is
+// rewritten as T so they compare directly with target sequences, and
+// sites on genome region targets are placed on the genome.
+func normalize(s TargetSite) TargetSite {
+	s.MiRNASeq = sequtil.ToDNA(s.MiRNASeq)
+	s.MiRNAAligned = sequtil.ToDNA(s.MiRNAAligned)
+	s.TargetAligned = sequtil.ToDNA(s.TargetAligned)
+	if iv, ok := region.Parse(s.TargetID); ok {
+		if g, ok := iv.Project(s.Start, s.End, s.Strand); ok {
+			s.Genome = &g
+		}
+	}
+	return s
+}
+
+func ungap(s string) string {
+	return strings.ReplaceAll(s, "-", "")
+}
+
+// Site converts the row into a TargetSite. psRNATarget prints both
+// fragments 5'->3', so the miRNA fragment is reversed to pair with the
+// target.
+func (p PsRNATarget) Site() TargetSite {
+	start, end := p.Coords().ToInternal(p.TargetStart, p.TargetEnd)
+	return normalize(TargetSite{
+		Tool:          ToolPsRNATarget,
+		MiRNAID:       p.MiRNA,
+		MiRNASeq:      ungap(p.MiRNAAligned),
+		TargetID:      p.Target,
+		Start:         start,
+		End:           end,
+		Strand:        "+",
+		Cleavage:      -1,
+		Score:         p.Expectation,
+		MiRNAAligned:  reverse(p.MiRNAAligned),
+		TargetAligned: p.TargetAligned,
+		Features:      p.Features(),
+		Raw:           p.Fields,
+		Origin:        p.Origin,
+	})
+}
+
+// Site converts the hit into a TargetSite. TAPIR prints the miRNA 3'->5'
+// so its sequence is reversed back. TAPIR searches each target as given,
+// so the site is on its plus strand even for targets named like
+// chr1:7410311-7412481_-, where the suffix is the genomic strand the
+// target sequence was taken from.
+func (t Tapir) Site() TargetSite {
+	raw := map[string]string{
+		"target":        t.Target,
+		"miRNA":         t.MiRNA,
+		"score":         strconv.FormatFloat(t.Score, 'g', -1, 64),
+		"mfe_ratio":     strconv.FormatFloat(t.MFERatio, 'g', -1, 64),
+		"start":         strconv.Itoa(t.Start),
+		"seed_gap":      strconv.Itoa(t.SeedGap),
+		"seed_mismatch": strconv.Itoa(t.SeedMismatch),
+		"seed_gu":       strconv.Itoa(t.SeedGU),
+		"gap":           strconv.Itoa(t.Gap),
+		"mismatch":      strconv.Itoa(t.Mismatch),
+		"gu":            strconv.Itoa(t.GU),
+		"miRNA_3'":      t.MiRNA3,
+		"aln":           t.Aln,
+		"target_5'":     t.Target5,
+	}
+	start, end := t.Coords().ToInternal(t.Start, t.End())
+	return normalize(TargetSite{
+		Tool:          ToolTapir,
+		MiRNAID:       t.MiRNAName,
+		MiRNASeq:      reverse(ungap(t.MiRNA3)),
+		TargetID:      t.Target,
+		Start:         start,
+		End:           end,
+		Strand:        "+",
+		Cleavage:      -1,
+		Score:         t.Score,
+		MiRNAAligned:  t.MiRNA3,
+		TargetAligned: t.Target5,
+		Match:         t.Aln,
+		Features:      t.Features(),
+		Raw:           raw,
+		Origin:        t.Origin,
+	})
+}
+
+// Site converts the row into a TargetSite. Slice_pos is the last base
+// before the cut, so in 0-based numbering it is the base the cut precedes.
+func (t TarHunter) Site() TargetSite {
+	start, end := t.Coords().ToInternal(t.StartPos, t.End())
+	return normalize(TargetSite{
+		Tool:          ToolTarHunter,
+		MiRNAID:       t.MiRNAID,
+		MiRNASeq:      t.MiRNASeq,
+		TargetID:      t.TargetID,
+		Start:         start,
+		End:           end,
+		Strand:        "+",
+		Cleavage:      t.SlicePos,
+		Score:         t.Score,
+		TargetAligned: t.TargetSeq,
+		Features:      t.Features(),
+		Raw:           t.Fields,
+		Origin:        t.Origin,
+	})
+}
+
+// Site converts the hit into a TargetSite. TargetFinder prints the query
+// 3'->5' under the target so its sequence is reversed back.
+func (t TargetFinder) Site() TargetSite {
+	start, end := t.Coords().ToInternal(t.Start, t.End)
+	return normalize(TargetSite{
+		Tool:          ToolTargetFinder,
+		MiRNAID:       t.Query,
+		MiRNASeq:      reverse(ungap(t.QueryAln)),
+		TargetID:      t.TargetID,
+		Start:         start,
+		End:           end,
+		Strand:        t.Strand,
+		Cleavage:      -1,
+		Score:         t.Score,
+		MiRNAAligned:  t.QueryAln,
+		TargetAligned: t.TargetAln,
+		Match:         t.Match,
+		Features:      t.Features(),
+		Raw:           t.Fields,
+		Origin:        t.Origin,
+	})
+}
+
+// Site converts the hit into a TargetSite. psRobot prints the query
+// 5'->3' over the base by base complement of the target, so the target
+// reads 3'->5' and all three alignment lines are reversed.
+func (p PsRobot) Site() TargetSite {
+	raw := map[string]string{
+		"smRNA":         p.SmRNA,
+		"score":         strconv.FormatFloat(p.Score, 'g', -1, 64),
+		"target":        p.Target,
+		"query_start":   strconv.Itoa(p.QueryStart),
+		"query_end":     strconv.Itoa(p.QueryEnd),
+		"subject_start": strconv.Itoa(p.SubjectStart),
+		"subject_end":   strconv.Itoa(p.SubjectEnd),
+		"query":         p.QueryAln,
+		"match":         p.Match,
+		"subject":       p.SubjectAln,
+	}
+	for k, v := range p.Fields {
+		raw[k] = v
+	}
+	start, end := p.Coords().ToInternal(p.SubjectStart, p.SubjectEnd)
+	return normalize(TargetSite{
+		Tool:          ToolPsRobot,
+		MiRNAID:       p.SmRNA,
+		MiRNASeq:      ungap(p.QueryAln),
+		TargetID:      p.Target,
+		Start:         start,
+		End:           end,
+		Strand:        "+",
+		Cleavage:      -1,
+		Score:         p.Score,
+		MiRNAAligned:  reverse(p.QueryAln),
+		TargetAligned: reverse(p.SubjectAln),
+		Match:         reverse(p.Match),
+		Features:      p.Features(),
+		Raw:           raw,
+		Origin:        p.Origin,
+	})
+}
+
+// Site converts the alignment into a TargetSite. The read itself is the
+// small RNA and the reference is the target.
+func (m PsRNAMap) Site() TargetSite {
+	start, end := m.Coords().ToInternal(m.Start, m.Stop)
+	return normalize(TargetSite{
+		Tool:     ToolPsRNAMap,
+		MiRNAID:  m.ReadID,
+		MiRNASeq: m.Read,
+		TargetID: m.Ref,
+		Start:    start,
+		End:      end,
+		Strand:   m.Strand,
+		Cleavage: -1,
+		Features: map[string]float64{},
+		Raw: map[string]string{
+			"id":     m.ReadID,
+			"ref":    m.Ref,
+			"strand": m.Strand,
+			"start":  strconv.Itoa(m.Start),
+			"stop":   strconv.Itoa(m.Stop),
+			"read":   m.Read,
+		},
+		Origin: m.Origin,
+	})
+}
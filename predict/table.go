@@ -0,0 +1,97 @@
+// Package predict parses the output of the miRNA target prediction tools
+// into typed records.
+package predict
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Origin records where a prediction was read from: the file, the line
+// its record starts on and the original line or block of text.
+type Origin struct {
+	File string
+	Line int
+	Text string
+}
+
+// normalizeColumn folds header spellings such as "Target_Acc." and
+// "UPE$" onto a lowercase key.
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimRight(name, ".$")
+}
+
+// columns maps normalized header names to their index in a row.
+type columns map[string]int
+
+func newColumns(header []string) columns {
+	cols := make(columns, len(header))
+	for i, name := range header {
+		cols[normalizeColumn(name)] = i
+	}
+	return cols
+}
+
+// require reports the first of names missing from the header.
+func (c columns) require(names ...string) error {
+	for _, name := range names {
+		if _, ok := c[name]; !ok {
+			return fmt.Errorf("missing %q column", name)
+		}
+	}
+	return nil
+}
+
+// row reads typed values out of one delimited line by column name. The
+// first conversion failure is kept in err and later reads are no-ops.
+type row struct {
+	cells  []string
+	header []string
+	cols   columns
+	err    error
+}
+
+func (r *row) str(name string) string {
+	i, ok := r.cols[name]
+	if !ok || i >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[i])
+}
+
+func (r *row) int(name string) int {
+	s := r.str(name)
+	if s == "" || r.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", name, err)
+	}
+	return n
+}
+
+func (r *row) float(name string) float64 {
+	s := r.str(name)
+	if s == "" || r.err != nil {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.err = fmt.Errorf("column %s: %w", name, err)
+	}
+	return f
+}
+
+// fields returns every cell keyed by its original header name.
+func (r *row) fields() map[string]string {
+	m := make(map[string]string, len(r.header))
+	for i, name := range r.header {
+		if i < len(r.cells) {
+			m[name] = strings.TrimSpace(r.cells[i])
+		}
+	}
+	return m
+}
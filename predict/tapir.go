@@ -0,0 +1,181 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Tapir is one hit block of TAPIR output.
+type Tapir struct {
+	Target string
+	// MiRNA is the raw GFF style attribute string of the miRNA line and
+	// Attributes its parsed key=value pairs.
+	MiRNA        string
+	Attributes   map[string]string
+	MiRNAName    string
+	Score        float64
+	MFERatio     float64
+	Start        int
+	SeedGap      int
+	SeedMismatch int
+	SeedGU       int
+	Gap          int
+	Mismatch     int
+	GU           int
+	MiRNA3       string
+	Aln          string
+	Target5      string
+	Origin       Origin
+}
+
+// End returns the last target position of the site, inclusive like
+// Start: Start plus the number of target bases in the alignment, less one.
+func (t Tapir) End() int {
+	return t.Start + len(strings.ReplaceAll(t.Target5, "-", "")) - 1
+}
+
+// Features returns the numeric fields of the hit for model input.
+func (t Tapir) Features() map[string]float64 {
+	return map[string]float64{
+		"score":         t.Score,
+		"mfe_ratio":     t.MFERatio,
+		"seed_gap":      float64(t.SeedGap),
+		"seed_mismatch": float64(t.SeedMismatch),
+		"seed_gu":       float64(t.SeedGU),
+		"gap":           float64(t.Gap),
+		"mismatch":      float64(t.Mismatch),
+		"gu":            float64(t.GU),
+	}
+}
+
+// parseAttributes splits "ID=x;Alias=y;Name=z" into a map, ignoring
+// entries without a value.
+func parseAttributes(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && k != "" {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+// ReadTapir parses TAPIR output, grouping each block that starts with a
+// "target" line into one record.
+func ReadTapir(r io.Reader) ([]Tapir, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	hits := []Tapir{}
+	var (
+		cur    *Tapir
+		lineNo int
+		valCol int
+		block  []string
+	)
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		if cur.Target5 == "" {
+			return fmt.Errorf("TAPIR: hit on %s before line %d has no target_5' alignment", cur.Target, lineNo)
+		}
+		cur.Origin.Text = strings.Join(block, "\n")
+		hits = append(hits, *cur)
+		return nil
+	}
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		key := strings.Fields(trimmed)[0]
+		value := strings.TrimSpace(strings.TrimPrefix(trimmed, key))
+		if key == "target" {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			cur = &Tapir{Target: value, Origin: Origin{Line: lineNo}}
+			block = append(block[:0], line)
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("TAPIR line %d: %q before the first target line", lineNo, key)
+		}
+		block = append(block, line)
+		var err error
+		switch key {
+		case "miRNA":
+			cur.MiRNA = value
+			cur.Attributes = parseAttributes(value)
+			cur.MiRNAName = cur.Attributes["Name"]
+			if cur.MiRNAName == "" {
+				cur.MiRNAName = value
+			}
+		case "score":
+			cur.Score, err = strconv.ParseFloat(value, 64)
+		case "mfe_ratio":
+			cur.MFERatio, err = strconv.ParseFloat(value, 64)
+		case "start":
+			cur.Start, err = strconv.Atoi(value)
+		case "seed_gap":
+			cur.SeedGap, err = strconv.Atoi(value)
+		case "seed_mismatch":
+			cur.SeedMismatch, err = strconv.Atoi(value)
+		case "seed_gu":
+			cur.SeedGU, err = strconv.Atoi(value)
+		case "gap":
+			cur.Gap, err = strconv.Atoi(value)
+		case "mismatch":
+			cur.Mismatch, err = strconv.Atoi(value)
+		case "gu":
+			cur.GU, err = strconv.Atoi(value)
+		case "miRNA_3'":
+			cur.MiRNA3 = value
+			valCol = strings.Index(line, value)
+		case "aln":
+			// The match line may begin with spaces for unpaired bases,
+			// so it is cut at the column the miRNA_3' string started.
+			if valCol > 0 && len(line) > valCol {
+				cur.Aln = line[valCol:]
+			} else {
+				cur.Aln = value
+			}
+		case "target_5'":
+			cur.Target5 = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("TAPIR line %d: %s: %w", lineNo, key, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// ReadTapirFile parses the TAPIR output at path.
+func ReadTapirFile(path string) ([]Tapir, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hits, err := ReadTapir(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range hits {
+		hits[i].Origin.File = path
+	}
+	return hits, nil
+}
@@ -0,0 +1,79 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadTapir(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/tapiranalyzer.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(data)
+	// An unpaired first miRNA base leaves the aln line starting with
+	// spaces after its label.
+	leading := strings.Replace(sample, "aln           ||...", "aln             ...", 1)
+
+	tests := []struct {
+		name string
+		in   string
+		aln  string
+		err  string
+	}{
+		{"sample", sample, "||...||||||||||||||||", ""},
+		{"aln with leading spaces", leading, "  ...||||||||||||||||", ""},
+		{"two hits", sample + "\n" + sample, "||...||||||||||||||||", ""},
+		{"no alignment", strings.Split(sample, "miRNA_3'")[0], "", "has no target_5' alignment"},
+		{"field before target", "score 3\n", "", "before the first target line"},
+		{"bad score", strings.Replace(sample, "score         3", "score         x", 1), "", "line 9: score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := ReadTapir(strings.NewReader(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.Count(tt.in, "\ntarget "); len(hits) != want {
+				t.Fatalf("got %d hits, want %d", len(hits), want)
+			}
+			h := hits[len(hits)-1]
+			if h.Target != "chr1:7410311-7412481_-" || h.MiRNAName != "ath-miR838" || h.Attributes["Alias"] != "MIMAT0004260" {
+				t.Errorf("got %s on %s, attributes %v", h.MiRNAName, h.Target, h.Attributes)
+			}
+			if h.Score != 3 || h.MFERatio != 0.74 || h.Start != 10 || h.End() != 30 || h.Mismatch != 3 {
+				t.Errorf("got score %g, mfe ratio %g, range %d-%d, %d mismatches", h.Score, h.MFERatio, h.Start, h.End(), h.Mismatch)
+			}
+			if h.MiRNA3 != "ACACGUUCUUCAUCUUCUUUU" || h.Aln != tt.aln || h.Target5 != "UGCUAAAGAAGUAGAAGAAAA" {
+				t.Errorf("got alignment\n%q\n%q\n%q", h.MiRNA3, h.Aln, h.Target5)
+			}
+			if !strings.HasPrefix(h.Origin.Text, "target ") || !strings.HasSuffix(h.Origin.Text, "UGCUAAAGAAGUAGAAGAAAA") {
+				t.Errorf("got origin text %q", h.Origin.Text)
+			}
+		})
+	}
+}
+
+func TestTapirSite(t *testing.T) {
+	hits, err := ReadTapirFile("../sample-files/tapiranalyzer.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := hits[0].Site()
+	if s.Start != 9 || s.End != 30 || s.Strand != "+" {
+		t.Errorf("got %d-%d %s, want 9-30 +", s.Start, s.End, s.Strand)
+	}
+	if s.MiRNASeq != "TTTTCTTCTACTTCTTGCACA" || s.MiRNAAligned != "ACACGTTCTTCATCTTCTTTT" || s.TargetAligned != "TGCTAAAGAAGTAGAAGAAAA" {
+		t.Errorf("got %s aligned as\n%s\n%s", s.MiRNASeq, s.MiRNAAligned, s.TargetAligned)
+	}
+	if s.Origin.Line != 7 {
+		t.Errorf("got origin line %d, want 7", s.Origin.Line)
+	}
+}
@@ -0,0 +1,287 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TargetFinder is one hit reported by TargetFinder in any of its output
+// modes.
+type TargetFinder struct {
+	Query      string
+	TargetID   string
+	TargetDesc string
+	Start      int
+	End        int
+	Strand     string
+	Score      float64
+	QueryAln   string
+	Match      string
+	TargetAln  string
+	Origin     Origin
+	// Fields holds the hit as reported, keyed by TargetFinder's names.
+	Fields map[string]string
+}
+
+// Features returns the numeric fields of the hit for model input.
+func (t TargetFinder) Features() map[string]float64 {
+	return map[string]float64{
+		"score": t.Score,
+	}
+}
+
+// splitTarget separates a TAIR style "AT2G33770.1 | Symbols: ... | ..."
+// target into its ID and description.
+func splitTarget(s string) (id, desc string) {
+	s = strings.TrimSpace(s)
+	if before, after, ok := strings.Cut(s, "|"); ok {
+		return strings.TrimSpace(before), strings.TrimSpace(after)
+	}
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+func normalizeStrand(s string) string {
+	switch strings.TrimSpace(s) {
+	case "+", "1", "+1":
+		return "+"
+	case "-", "-1":
+		return "-"
+	}
+	return "."
+}
+
+func isStrand(s string) bool {
+	switch s {
+	case "+", "-", ".", "1", "-1", "+1":
+		return true
+	}
+	return false
+}
+
+func isInt(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+var targetFinderHeader = regexp.MustCompile(`^query=(.*?), target=(.*), score=([^,]+), range=(\d+)-(\d+), strand=(\S+)`)
+
+// ReadTargetFinder parses TargetFinder output. The default block layout
+// ("query=..., target=..." followed by the alignment), the -p table
+// layout and the -p gff layout are all recognised line by line, so files
+// mixing them parse as well.
+func ReadTargetFinder(r io.Reader) ([]TargetFinder, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	hits := []TargetFinder{}
+	var (
+		lineNo int
+		block  *TargetFinder
+		alnCol int
+		alnRow int
+	)
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "No results") {
+			continue
+		}
+		if block != nil {
+			block.Origin.Text += "\n" + line
+			switch alnRow {
+			case 0:
+				seq := strings.Fields(trimmed)
+				if len(seq) >= 3 && seq[0] == "target" {
+					block.TargetAln = seq[2]
+					alnCol = strings.Index(line, seq[2])
+				}
+			case 1:
+				if alnCol < len(line) {
+					block.Match = line[alnCol:]
+				}
+			case 2:
+				seq := strings.Fields(trimmed)
+				if len(seq) >= 3 && seq[0] == "query" {
+					block.QueryAln = seq[2]
+				}
+			}
+			alnRow++
+			if alnRow == 3 {
+				block.Fields["target_seq"] = block.TargetAln
+				block.Fields["homology"] = block.Match
+				block.Fields["query_seq"] = block.QueryAln
+				hits = append(hits, *block)
+				block = nil
+			}
+			continue
+		}
+		var (
+			hit TargetFinder
+			err error
+		)
+		if m := targetFinderHeader.FindStringSubmatch(trimmed); m != nil {
+			hit, err = targetFinderBlock(m)
+			if err == nil {
+				hit.Origin = Origin{Line: lineNo, Text: line}
+				block, alnRow = &hit, 0
+				continue
+			}
+		} else {
+			hit, err = targetFinderRow(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("TargetFinder line %d: %w", lineNo, err)
+		}
+		hit.Origin = Origin{Line: lineNo, Text: line}
+		hits = append(hits, hit)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if block != nil {
+		return nil, fmt.Errorf("TargetFinder: alignment of %s on %s is truncated", block.Query, block.TargetID)
+	}
+	return hits, nil
+}
+
+func targetFinderBlock(m []string) (TargetFinder, error) {
+	hit := TargetFinder{
+		Query:  m[1],
+		Strand: normalizeStrand(m[6]),
+		Fields: map[string]string{
+			"query":  m[1],
+			"target": m[2],
+			"score":  m[3],
+			"start":  m[4],
+			"end":    m[5],
+			"strand": m[6],
+		},
+	}
+	hit.TargetID, hit.TargetDesc = splitTarget(m[2])
+	var err error
+	if hit.Score, err = strconv.ParseFloat(m[3], 64); err != nil {
+		return hit, fmt.Errorf("score: %w", err)
+	}
+	hit.Start, _ = strconv.Atoi(m[4])
+	hit.End, _ = strconv.Atoi(m[5])
+	return hit, nil
+}
+
+// targetFinderRow parses a -p table or -p gff line. Table rows are
+// query, target, start, end, strand, score, target_seq, homology and
+// query_seq, the query printed 3'->5' as in the default layout; when the
+// tabs have been lost the target description is recovered as everything
+// between the query and the last seven fields.
+func targetFinderRow(line string) (TargetFinder, error) {
+	cells := strings.Split(line, "\t")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	if len(cells) >= 9 && isInt(cells[3]) && isInt(cells[4]) && isStrand(cells[6]) {
+		return targetFinderGFF(cells)
+	}
+	if len(cells) < 9 {
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			return TargetFinder{}, fmt.Errorf("unrecognised line %q", line)
+		}
+		n := len(fields)
+		cells = append([]string{fields[0], strings.Join(fields[1:n-7], " ")}, fields[n-7:]...)
+	}
+	names := []string{"query", "target", "start", "end", "strand", "score", "target_seq", "homology", "query_seq"}
+	hit := TargetFinder{
+		Query:     cells[0],
+		Strand:    normalizeStrand(cells[4]),
+		TargetAln: cells[6],
+		Match:     cells[7],
+		QueryAln:  cells[8],
+		Fields:    make(map[string]string, len(names)),
+	}
+	for i, name := range names {
+		hit.Fields[name] = cells[i]
+	}
+	hit.TargetID, hit.TargetDesc = splitTarget(cells[1])
+	var err error
+	if hit.Start, err = strconv.Atoi(cells[2]); err != nil {
+		return hit, fmt.Errorf("start: %w", err)
+	}
+	if hit.End, err = strconv.Atoi(cells[3]); err != nil {
+		return hit, fmt.Errorf("end: %w", err)
+	}
+	if hit.Score, err = strconv.ParseFloat(cells[5], 64); err != nil {
+		return hit, fmt.Errorf("score: %w", err)
+	}
+	return hit, nil
+}
+
+// targetFinderGFF parses a -p gff line. The miRNA, score and alignment
+// are read from the attribute column, where targetfinder.pl names them
+// smallRNA, score, target_seq, homology and smallRNA_seq.
+func targetFinderGFF(cells []string) (TargetFinder, error) {
+	attrs := map[string]string{}
+	for _, part := range strings.Split(cells[8], ";") {
+		part = strings.TrimSpace(part)
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			k, v, _ = strings.Cut(part, " ")
+		}
+		if k != "" {
+			attrs[strings.ToLower(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := attrs[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	hit := TargetFinder{
+		Query:     first("smallrna", "mirna", "query", "name"),
+		Strand:    normalizeStrand(cells[6]),
+		QueryAln:  first("smallrna_seq", "mirna_seq", "query_seq"),
+		Match:     first("homology", "match"),
+		TargetAln: first("target_seq"),
+		Fields:    attrs,
+	}
+	hit.TargetID, hit.TargetDesc = splitTarget(cells[0])
+	hit.Start, _ = strconv.Atoi(cells[3])
+	hit.End, _ = strconv.Atoi(cells[4])
+	score := first("score")
+	if score == "" && cells[5] != "." {
+		score = cells[5]
+	}
+	if score != "" {
+		var err error
+		if hit.Score, err = strconv.ParseFloat(score, 64); err != nil {
+			return hit, fmt.Errorf("score: %w", err)
+		}
+	}
+	return hit, nil
+}
+
+// ReadTargetFinderFile parses the TargetFinder output at path.
+func ReadTargetFinderFile(path string) ([]TargetFinder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hits, err := ReadTargetFinder(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range hits {
+		hits[i].Origin.File = path
+	}
+	return hits, nil
+}
@@ -0,0 +1,68 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	miR399aTarget   = "UAGGGCAAAUCUUCUUUGGCA"
+	miR399aHomology = ".:::::::::::.::::::::"
+	miR399aQuery    = "GUCCCGUUUAGAGGAAACCGU"
+	pho2Desc        = "Symbols: UBC24, ATUBC24, PHO2 | phosphate 2 | chr2:14277558-14283040 REVERSE LEN"
+)
+
+func TestReadTargetFinder(t *testing.T) {
+	table, err := os.ReadFile("../sample-files/targetfinder.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Copied out of a terminal the tabs of the table become spaces and
+	// the description runs into the other columns.
+	untabbed := strings.ReplaceAll(string(table), "\t", " ")
+
+	tests := []struct {
+		name string
+		read func() ([]TargetFinder, error)
+		desc string
+	}{
+		{"block", fileReader(ReadTargetFinderFile, "../sample-files/targetfinder-classic.txt"), pho2Desc},
+		{"table", fileReader(ReadTargetFinderFile, "../sample-files/targetfinder.txt"), pho2Desc},
+		{"gff", fileReader(ReadTargetFinderFile, "../sample-files/targetfinder-gff.txt"), ""},
+		{"untabbed table", func() ([]TargetFinder, error) { return ReadTargetFinder(strings.NewReader(untabbed)) }, pho2Desc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := tt.read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != 1 {
+				t.Fatalf("got %d hits, want 1", len(hits))
+			}
+			h := hits[0]
+			if h.Query != "miR399a" || h.TargetID != "AT2G33770.1" || h.TargetDesc != tt.desc {
+				t.Errorf("got query %q, target %q, description %q", h.Query, h.TargetID, h.TargetDesc)
+			}
+			if h.Start != 10 || h.End != 37 || h.Strand != "+" || h.Score != 1.5 {
+				t.Errorf("got range %d-%d strand %s score %g, want 10-37 + 1.5", h.Start, h.End, h.Strand, h.Score)
+			}
+			if h.TargetAln != miR399aTarget || h.Match != miR399aHomology || h.QueryAln != miR399aQuery {
+				t.Errorf("got alignment\n%s\n%s\n%s", h.TargetAln, h.Match, h.QueryAln)
+			}
+			if h.Origin.Line != 1 {
+				t.Errorf("got origin line %d, want 1", h.Origin.Line)
+			}
+			s := h.Site()
+			if s.MiRNASeq != "TGCCAAAGGAGATTTGCCCTG" || s.Start != 9 || s.End != 37 {
+				t.Errorf("got site %s at %d-%d", s.MiRNASeq, s.Start, s.End)
+			}
+		})
+	}
+}
+
+// fileReader adapts a Read*File function to the table's read field.
+func fileReader[T any](read func(string) ([]T, error), path string) func() ([]T, error) {
+	return func() ([]T, error) { return read(path) }
+}
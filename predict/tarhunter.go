@@ -0,0 +1,132 @@
+package predict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// TarHunter is one row of TarHunter tab-separated output.
+type TarHunter struct {
+	TargetID  string
+	TargetSeq string
+	MiRNAID   string
+	MiRNASeq  string
+	TotalMisp float64
+	Score     float64
+	SeedMisp  float64
+	Cleavage  bool
+	StartPos  int
+	SlicePos  int
+	Origin    Origin
+	// Fields holds every column of the row keyed by its header name.
+	Fields map[string]string
+}
+
+// End returns the last target position of the site, inclusive like
+// Start_pos.
+func (t TarHunter) End() int {
+	return t.StartPos + len(t.TargetSeq) - 1
+}
+
+// Features returns the numeric columns of the row for model input.
+func (t TarHunter) Features() map[string]float64 {
+	cleavage := 0.0
+	if t.Cleavage {
+		cleavage = 1
+	}
+	return map[string]float64{
+		"total_misp": t.TotalMisp,
+		"score":      t.Score,
+		"seed_misp":  t.SeedMisp,
+		"cleavage":   cleavage,
+		"slice_pos":  float64(t.SlicePos),
+	}
+}
+
+var tarHunterColumns = []string{
+	"targ_id", "targ_seq", "mir_id", "mir_seq", "total_misp",
+	"score", "seed_misp", "cleavage", "start_pos", "slice_pos",
+}
+
+// ReadTarHunter parses TarHunter output. Repeated header rows, as left by
+// concatenating several runs, are skipped.
+func ReadTarHunter(r io.Reader) ([]TarHunter, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var (
+		header []string
+		cols   columns
+		lineNo int
+	)
+	rows := []TarHunter{}
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cells := strings.Split(line, "\t")
+		if normalizeColumn(cells[0]) == "targ_id" {
+			if header == nil {
+				header = cells
+				cols = newColumns(header)
+				if err := cols.require(tarHunterColumns...); err != nil {
+					return nil, fmt.Errorf("TarHunter line %d: %w", lineNo, err)
+				}
+			}
+			continue
+		}
+		if header == nil {
+			return nil, fmt.Errorf("TarHunter line %d: data before the header row", lineNo)
+		}
+		if len(cells) < len(header) {
+			return nil, fmt.Errorf("TarHunter line %d: expected %d columns, got %d", lineNo, len(header), len(cells))
+		}
+		rw := &row{cells: cells, header: header, cols: cols}
+		rec := TarHunter{
+			TargetID:  rw.str("targ_id"),
+			TargetSeq: rw.str("targ_seq"),
+			MiRNAID:   rw.str("mir_id"),
+			MiRNASeq:  rw.str("mir_seq"),
+			TotalMisp: rw.float("total_misp"),
+			Score:     rw.float("score"),
+			SeedMisp:  rw.float("seed_misp"),
+			Cleavage:  strings.EqualFold(rw.str("cleavage"), "yes"),
+			StartPos:  rw.int("start_pos"),
+			SlicePos:  rw.int("slice_pos"),
+			Fields:    rw.fields(),
+			Origin:    Origin{Line: lineNo, Text: line},
+		}
+		if rw.err != nil {
+			return nil, fmt.Errorf("TarHunter line %d: %w", lineNo, rw.err)
+		}
+		rows = append(rows, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("TarHunter: no header row found")
+	}
+	return rows, nil
+}
+
+// ReadTarHunterFile parses the TarHunter output at path.
+func ReadTarHunterFile(path string) ([]TarHunter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rows, err := ReadTarHunter(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range rows {
+		rows[i].Origin.File = path
+	}
+	return rows, nil
+}
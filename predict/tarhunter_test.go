@@ -0,0 +1,75 @@
+package predict
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadTarHunter(t *testing.T) {
+	data, err := os.ReadFile("../sample-files/tarhunter.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := string(data)
+	header, body, _ := strings.Cut(sample, "\n")
+
+	tests := []struct {
+		name string
+		in   string
+		rows int
+		err  string
+	}{
+		{"sample", sample, 1, ""},
+		{"repeated header", sample + sample, 2, ""},
+		{"comment before header", "# TarHunter run\n" + sample, 1, ""},
+		{"data before header", body, 0, "data before the header row"},
+		{"no header", "", 0, "no header row"},
+		{"short row", header + "\nAT3G57920.1\tGUGC\n", 0, "expected 10 columns, got 2"},
+		{"bad start", strings.Replace(sample, "\t10\t31", "\tten\t31", 1), 0, "column start_pos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadTarHunter(strings.NewReader(tt.in))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != tt.rows {
+				t.Fatalf("got %d rows, want %d", len(rows), tt.rows)
+			}
+			r := rows[0]
+			if r.TargetID != "AT3G57920.1" || r.MiRNAID != "ath-miR157a-5p" || r.MiRNASeq != "UUGACAGAAGAUAGAGAGCAC" {
+				t.Errorf("got %s %s on %s", r.MiRNAID, r.MiRNASeq, r.TargetID)
+			}
+			if r.TotalMisp != 1 || r.Score != 2 || r.SeedMisp != 0 || !r.Cleavage {
+				t.Errorf("got total %g, score %g, seed %g, cleavage %v", r.TotalMisp, r.Score, r.SeedMisp, r.Cleavage)
+			}
+			if r.StartPos != 10 || r.End() != 30 || r.SlicePos != 31 {
+				t.Errorf("got %d-%d sliced at %d", r.StartPos, r.End(), r.SlicePos)
+			}
+		})
+	}
+}
+
+func TestTarHunterSite(t *testing.T) {
+	rows, err := ReadTarHunterFile("../sample-files/tarhunter.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := rows[0].Site()
+	if s.Start != 9 || s.End != 30 || s.Cleavage != 31 || s.Strand != "+" {
+		t.Errorf("got %d-%d cut before %d on %s", s.Start, s.End, s.Cleavage, s.Strand)
+	}
+	if s.MiRNASeq != "TTGACAGAAGATAGAGAGCAC" || s.TargetAligned != "GTGCTCTCTCTCTTCTGTCAA" {
+		t.Errorf("got %s against %s", s.MiRNASeq, s.TargetAligned)
+	}
+	if s.Raw["Slice_pos"] != "31" || s.Origin.Line != 2 {
+		t.Errorf("got raw %v from line %d", s.Raw, s.Origin.Line)
+	}
+}
@@ -0,0 +1,105 @@
+// Package region parses genome region target IDs such as
+// chr1:7410311-7412481_- and maps positions within them back onto the
+// genome.
+package region
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Interval is a stretch of a genome, 0-based and half-open. Strand is
+// "+", "-" or "." when the ID did not give one.
+type Interval struct {
+	Chrom  string
+	Start  int
+	End    int
+	Strand string
+}
+
+func (iv Interval) String() string {
+	return fmt.Sprintf("%s:%d-%d(%s)", iv.Chrom, iv.Start+1, iv.End, iv.Strand)
+}
+
+// Len returns the number of bases in the interval.
+func (iv Interval) Len() int {
+	return iv.End - iv.Start
+}
+
+// Overlaps reports whether iv and o share at least one base on the same
+// chromosome, ignoring strand.
+func (iv Interval) Overlaps(o Interval) bool {
+	return iv.Chrom == o.Chrom && iv.Start < o.End && o.Start < iv.End
+}
+
+// idPattern matches chrom:start-end optionally followed by _strand (as
+// written by psRNATarget and TAPIR) or (strand).
+var idPattern = regexp.MustCompile(`^(.+):([0-9,]+)-([0-9,]+)(?:_([+-]?)|\(([+.-])\))?$`)
+
+// Parse reads a region ID. The positions in the ID are 1-based and
+// inclusive, as in samtools and genome browser notation.
+func Parse(id string) (Interval, bool) {
+	m := idPattern.FindStringSubmatch(id)
+	if m == nil {
+		return Interval{}, false
+	}
+	start, err := strconv.Atoi(stripCommas(m[2]))
+	if err != nil {
+		return Interval{}, false
+	}
+	end, err := strconv.Atoi(stripCommas(m[3]))
+	if err != nil || start < 1 || end < start {
+		return Interval{}, false
+	}
+	strand := m[4] + m[5]
+	if strand == "" {
+		strand = "."
+	}
+	return Interval{Chrom: m[1], Start: start - 1, End: end, Strand: strand}, true
+}
+
+func stripCommas(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != ',' {
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
+
+// Project maps [start, end) on strand of the region's own sequence onto
+// the genome. A minus strand region's sequence is the reverse complement
+// of the genome, so positions are counted back from its end and the
+// strand flips. It reports false when the site does not lie within the
+// region, as happens when the ID does not describe the sequence.
+func (iv Interval) Project(start, end int, strand string) (Interval, bool) {
+	if start < 0 || end > iv.Len() || start > end {
+		return Interval{}, false
+	}
+	if iv.Strand == "-" {
+		return Interval{
+			Chrom:  iv.Chrom,
+			Start:  iv.End - end,
+			End:    iv.End - start,
+			Strand: flip(strand),
+		}, true
+	}
+	return Interval{
+		Chrom:  iv.Chrom,
+		Start:  iv.Start + start,
+		End:    iv.Start + end,
+		Strand: strand,
+	}, true
+}
+
+func flip(strand string) string {
+	switch strand {
+	case "+":
+		return "-"
+	case "-":
+		return "+"
+	}
+	return strand
+}
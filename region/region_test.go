@@ -0,0 +1,84 @@
+package region
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		id   string
+		want Interval
+		ok   bool
+	}{
+		{"chr1:7410311-7412481_-", Interval{"chr1", 7410310, 7412481, "-"}, true},
+		{"chr5:6013917-6014399_+", Interval{"chr5", 6013916, 6014399, "+"}, true},
+		// psRNATarget writes a bare underscore for an unstranded region.
+		{"chr5:6013917-6014399_", Interval{"chr5", 6013916, 6014399, "."}, true},
+		{"chr2:1,000-2,000(-)", Interval{"chr2", 999, 2000, "-"}, true},
+		{"chr2:1000-2000(.)", Interval{"chr2", 999, 2000, "."}, true},
+		{"chr2:1000-2000", Interval{"chr2", 999, 2000, "."}, true},
+		{"scaffold_12:5-5_-", Interval{"scaffold_12", 4, 5, "-"}, true},
+		{"AT1G01010.1", Interval{}, false},
+		{"chr1:0-5", Interval{}, false},
+		{"chr1:10-5", Interval{}, false},
+		{"chr1:1-5_x", Interval{}, false},
+		{"chr1:1-5(x)", Interval{}, false},
+		{":1-5", Interval{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.id)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+	if got := (Interval{"chr1", 7410310, 7412481, "-"}).String(); got != "chr1:7410311-7412481(-)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		iv         Interval
+		start, end int
+		strand     string
+		want       Interval
+		ok         bool
+	}{
+		{"plus", Interval{"c", 100, 110, "+"}, 2, 5, "+", Interval{"c", 102, 105, "+"}, true},
+		{"unstranded", Interval{"c", 100, 110, "."}, 2, 5, "-", Interval{"c", 102, 105, "-"}, true},
+		// The region's sequence reads from base 110 down, so its bases
+		// 2..4 are genome bases 107..105 on the other strand.
+		{"minus", Interval{"c", 100, 110, "-"}, 2, 5, "+", Interval{"c", 105, 108, "-"}, true},
+		{"minus flips minus", Interval{"c", 100, 110, "-"}, 2, 5, "-", Interval{"c", 105, 108, "+"}, true},
+		{"minus whole region", Interval{"c", 100, 110, "-"}, 0, 10, "+", Interval{"c", 100, 110, "-"}, true},
+		{"past the end", Interval{"c", 100, 110, "+"}, 8, 11, "+", Interval{}, false},
+		{"minus past the end", Interval{"c", 100, 110, "-"}, 8, 11, "+", Interval{}, false},
+		{"before the start", Interval{"c", 100, 110, "+"}, -1, 2, "+", Interval{}, false},
+		{"reversed", Interval{"c", 100, 110, "+"}, 5, 4, "+", Interval{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.iv.Project(tt.start, tt.end, tt.strand)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("got %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	a := Interval{"c", 100, 110, "+"}
+	tests := []struct {
+		b    Interval
+		want bool
+	}{
+		{Interval{"c", 109, 120, "-"}, true},
+		{Interval{"c", 110, 120, "+"}, false},
+		{Interval{"c", 90, 100, "+"}, false},
+		{Interval{"d", 100, 110, "+"}, false},
+	}
+	for _, tt := range tests {
+		if got := a.Overlaps(tt.b); got != tt.want {
+			t.Errorf("%v overlaps %v = %v", a, tt.b, got)
+		}
+	}
+}
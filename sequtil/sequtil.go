@@ -0,0 +1,124 @@
+// Package sequtil holds nucleotide sequence helpers shared by the
+// extractors: IUPAC aware complements, RNA/DNA conversion and strand
+// aware fetching from a fasta.Source.
+package sequtil
+
+import (
+	"fmt"
+
+	"github.com/go-microRNAs/fasta"
+)
+
+// iupac lists every IUPAC nucleotide code with its complement. U pairs
+// with A like T does.
+var iupac = map[byte]byte{
+	'A': 'T', 'C': 'G', 'G': 'C', 'T': 'A', 'U': 'A',
+	'R': 'Y', 'Y': 'R', 'S': 'S', 'W': 'W', 'K': 'M', 'M': 'K',
+	'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D', 'N': 'N',
+	'-': '-', '.': '.',
+}
+
+var complement [256]byte
+
+func init() {
+	for i := range complement {
+		complement[i] = 'N'
+	}
+	for b, c := range iupac {
+		complement[b] = c
+		if b >= 'A' && b <= 'Z' {
+			complement[b+'a'-'A'] = c + 'a' - 'A'
+		}
+	}
+}
+
+// IsIUPAC reports whether b is an IUPAC nucleotide code or gap, in
+// either case.
+func IsIUPAC(b byte) bool {
+	if b >= 'a' && b <= 'z' {
+		b -= 'a' - 'A'
+	}
+	_, ok := iupac[b]
+	return ok
+}
+
+// Validate returns an error naming the first character of seq that is
+// not an IUPAC nucleotide code.
+func Validate(seq string) error {
+	for i := 0; i < len(seq); i++ {
+		if !IsIUPAC(seq[i]) {
+			return fmt.Errorf("invalid nucleotide %q at position %d", seq[i], i+1)
+		}
+	}
+	return nil
+}
+
+// Complement complements every base of seq, keeping its case. Anything
+// that is not an IUPAC code becomes N.
+func Complement(seq string) string {
+	out := make([]byte, len(seq))
+	for i := 0; i < len(seq); i++ {
+		out[i] = complement[seq[i]]
+	}
+	return string(out)
+}
+
+// ReverseComplement returns the reverse complement of seq, keeping the
+// case of each base. U is complemented to A and the result uses T.
+func ReverseComplement(seq string) string {
+	out := make([]byte, len(seq))
+	for i := 0; i < len(seq); i++ {
+		out[len(seq)-1-i] = complement[seq[i]]
+	}
+	return string(out)
+}
+
+func replaceBase(seq string, from, to byte) string {
+	out := []byte(seq)
+	for i, b := range out {
+		switch b {
+		case from:
+			out[i] = to
+		case from + 'a' - 'A':
+			out[i] = to + 'a' - 'A'
+		}
+	}
+	return string(out)
+}
+
+// ToDNA rewrites U as T.
+func ToDNA(seq string) string {
+	return replaceBase(seq, 'U', 'T')
+}
+
+// ToRNA rewrites T as U.
+func ToRNA(seq string) string {
+	return replaceBase(seq, 'T', 'U')
+}
+
+// Normalize converts seq to the DNA alphabet and replaces anything that
+// is not an IUPAC code with N, keeping soft-masked case.
+func Normalize(seq string) string {
+	out := []byte(ToDNA(seq))
+	for i, b := range out {
+		if !IsIUPAC(b) {
+			out[i] = 'N'
+		}
+	}
+	return string(out)
+}
+
+// Fetch returns [start, end) of the sequence id read 5' to 3' on strand:
+// as stored for "+" and reverse complemented for "-". The result is
+// normalized to DNA.
+func Fetch(src fasta.Source, id string, start, end int, strand string) (string, error) {
+	seq, err := src.Fetch(id, start, end)
+	if err != nil {
+		return "", err
+	}
+	seq = Normalize(seq)
+	if strand == "-" {
+		seq = ReverseComplement(seq)
+	}
+	return seq, nil
+}
@@ -0,0 +1,64 @@
+package sequtil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-microRNAs/fasta"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		in, dna, rna, rc string
+	}{
+		{"ACGU", "ACGT", "ACGU", "ACGT"},
+		{"acgtN", "acgtN", "acguN", "Nacgt"},
+		{"RYKM-", "RYKM-", "RYKM-", "-KMRY"},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ToDNA(tt.in); got != tt.dna {
+			t.Errorf("ToDNA(%q) = %q, want %q", tt.in, got, tt.dna)
+		}
+		if got := ToRNA(tt.in); got != tt.rna {
+			t.Errorf("ToRNA(%q) = %q, want %q", tt.in, got, tt.rna)
+		}
+		if got := ReverseComplement(tt.in); got != tt.rc {
+			t.Errorf("ReverseComplement(%q) = %q, want %q", tt.in, got, tt.rc)
+		}
+	}
+	if got := Complement("ACGTX"); got != "TGCAN" {
+		t.Errorf("Complement gives %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("ACGUNacgtn-"); err != nil {
+		t.Error(err)
+	}
+	if err := Validate("ACXG"); err == nil || !strings.Contains(err.Error(), "position 3") {
+		t.Errorf("got %v", err)
+	}
+	if got := Normalize("acUX*"); got != "acTNN" {
+		t.Errorf("Normalize gives %q", got)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	store := fasta.NewStore([]fasta.Record{{ID: "t", Seq: "AACCGUXT"}})
+	tests := []struct {
+		strand, want string
+	}{
+		{"+", "CCGTN"},
+		{"-", "NACGG"},
+	}
+	for _, tt := range tests {
+		got, err := Fetch(store, "t", 2, 7, tt.strand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Fetch on %s = %q, want %q", tt.strand, got, tt.want)
+		}
+	}
+}
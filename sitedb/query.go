@@ -0,0 +1,110 @@
+package sitedb
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Filter selects sites by miRNA, target and tool. Empty fields match
+// everything; values containing * or ? are matched as globs, so
+// AT2G33770* finds every isoform of a gene.
+type Filter struct {
+	MiRNA  string
+	Target string
+	Tool   string
+}
+
+func (f Filter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	for _, c := range []struct{ column, value string }{
+		{"mirna_id", f.MiRNA},
+		{"target_id", f.Target},
+		{"tool", f.Tool},
+	} {
+		switch {
+		case c.value == "":
+			continue
+		case strings.ContainsAny(c.value, "*?"):
+			conds = append(conds, c.column+" GLOB ?")
+		default:
+			conds = append(conds, c.column+" = ?")
+		}
+		args = append(args, c.value)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// SiteColumns are the columns Sites prints.
+var SiteColumns = []string{"tool", "mirna_id", "target_id", "start", "end", "strand", "score", "site"}
+
+// PairColumns are the columns Pairs prints.
+var PairColumns = []string{"mirna_id", "target_id", "tools", "n_tools", "n_sites", "best_scores"}
+
+// Sites writes the matching sites as tab separated rows with a header.
+func Sites(path string, f Filter, w io.Writer) error {
+	where, args := f.where()
+	query := `SELECT ` + strings.Join(SiteColumns, ", ") + ` FROM sites` + where +
+		` ORDER BY mirna_id, target_id, start, tool`
+	return run(path, query, args, SiteColumns, w)
+}
+
+// Pairs writes one row per matching miRNA and target with the tools
+// that predicted the pair, how many sites they gave and each tool's
+// lowest score as tool:score, answering which miRNAs target a gene
+// across all tools. Scores are only compared within a tool, since every
+// tool has its own scale; tools without scores are listed bare.
+func Pairs(path string, f Filter, w io.Writer) error {
+	where, args := f.where()
+	query := `SELECT mirna_id, target_id, group_concat(tool, ',' ORDER BY tool), count(*), sum(n_sites),
+			group_concat(CASE WHEN best IS NULL THEN tool ELSE tool || ':' || printf('%g', best) END, ',' ORDER BY tool)
+		FROM (
+			SELECT mirna_id, target_id, tool, count(*) AS n_sites, min(score) AS best
+			FROM sites` + where + `
+			GROUP BY mirna_id, target_id, tool
+		)
+		GROUP BY mirna_id, target_id
+		ORDER BY count(*) DESC, mirna_id, target_id`
+	return run(path, query, args, PairColumns, w)
+}
+
+func run(path, query string, args []any, header []string, w io.Writer) error {
+	db, err := sql.Open("sqlite", uri(path, "mode=ro"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer rows.Close()
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+	vals := make([]sql.NullString, len(header))
+	ptrs := make([]any, len(header))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	cells := make([]string, len(header))
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		for i, v := range vals {
+			cells[i] = v.String
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
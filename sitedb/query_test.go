@@ -0,0 +1,58 @@
+package sitedb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-microRNAs/predict"
+)
+
+func TestPairs(t *testing.T) {
+	// URI syntax in the directory name must not change the file opened.
+	dir := filepath.Join(t.TempDir(), "runs?mode=memory#1 %41")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "sites.sqlite")
+	w, err := Create(path, predict.OneBased)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := func(tool, mirna, target string, score float64) predict.TargetSite {
+		return predict.TargetSite{
+			Tool: tool, MiRNAID: mirna, TargetID: target, Start: 10, End: 31, Strand: "+",
+			Score: score, Cleavage: -1, Origin: predict.Origin{File: tool + ".txt"},
+		}
+	}
+	for _, s := range []predict.TargetSite{
+		site(predict.ToolTargetFinder, "miR399a", "AT2G33770.1", 3),
+		site(predict.ToolTargetFinder, "miR399a", "AT2G33770.1", 1.5),
+		site(predict.ToolTapir, "miR399a", "AT2G33770.1", 4),
+		site(predict.ToolPsRNAMap, "miR399a", "AT2G33770.1", 0),
+		site(predict.ToolTargetFinder, "miR156a", "AT1G27370.1", 0.5),
+	} {
+		if err := w.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Pairs(path, Filter{Target: "AT*"}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	// Each tool's best score is its own lowest; psRNAmap gives none.
+	want := "mirna_id\ttarget_id\ttools\tn_tools\tn_sites\tbest_scores\n" +
+		"miR399a\tAT2G33770.1\tTAPIR,TargetFinder,psRNAmap\t3\t4\tTAPIR:4,TargetFinder:1.5,psRNAmap\n" +
+		"miR156a\tAT1G27370.1\tTargetFinder\t1\t1\tTargetFinder:0.5\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
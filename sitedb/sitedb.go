@@ -0,0 +1,311 @@
+// Package sitedb keeps target sites in a SQLite database so predictions
+// of several tools can be asked together which miRNAs target a gene.
+//
+// Every site is a row of the sites table, its feature values rows of
+// features, and the fields of the record it was read from a row of the
+// table named after its tool (psrnatarget, tapir, tarhunter,
+// targetfinder, psrobot or psrnamap), keyed by site_id. Runs writing to
+// the same file add to it, except that the sites a tool read from a
+// prediction file replace those of an earlier run on the same file.
+package sitedb
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-microRNAs/predict"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sites (
+	id             INTEGER PRIMARY KEY,
+	tool           TEXT NOT NULL,
+	mirna_id       TEXT NOT NULL,
+	mirna_seq      TEXT,
+	target_id      TEXT NOT NULL,
+	start          INTEGER NOT NULL,
+	end            INTEGER NOT NULL,
+	strand         TEXT,
+	cleavage       INTEGER,
+	score          REAL,
+	mirna_aligned  TEXT,
+	target_aligned TEXT,
+	match          TEXT,
+	site           TEXT,
+	upstream       TEXT,
+	downstream     TEXT,
+	upstream_pad   INTEGER,
+	downstream_pad INTEGER,
+	chrom          TEXT,
+	genome_start   INTEGER,
+	genome_end     INTEGER,
+	genome_strand  TEXT,
+	source_file    TEXT,
+	source_line    INTEGER
+);
+CREATE TABLE IF NOT EXISTS features (
+	site_id INTEGER NOT NULL REFERENCES sites(id),
+	name    TEXT NOT NULL,
+	value   REAL,
+	PRIMARY KEY (site_id, name)
+);
+`
+
+// indexes are created on Close, after the bulk inserts.
+const indexes = `
+CREATE INDEX IF NOT EXISTS sites_mirna ON sites(mirna_id);
+CREATE INDEX IF NOT EXISTS sites_target ON sites(target_id);
+CREATE INDEX IF NOT EXISTS sites_tool ON sites(tool);
+`
+
+// Writer adds sites to a database inside one transaction.
+type Writer struct {
+	db       *sql.DB
+	tx       *sql.Tx
+	coords   predict.Convention
+	site     *sql.Stmt
+	feature  *sql.Stmt
+	tables   map[string]map[string]bool
+	toolRows map[string]*sql.Stmt
+	replaced map[[2]string]bool
+}
+
+// Create opens or creates the database at path for writing. Positions
+// are stored in the coords numbering, which is recorded in the meta
+// table; adding to a database kept in the other numbering fails.
+func Create(path string, coords predict.Convention) (*Writer, error) {
+	db, err := sql.Open("sqlite", uri(path, ""))
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{db: db, coords: coords, tables: map[string]map[string]bool{}, toolRows: map[string]*sql.Stmt{}, replaced: map[[2]string]bool{}}
+	if err := w.init(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// uri returns the file: URI SQLite opens path by, escaping the ?, #
+// and % a plain file name would have taken as URI syntax.
+func uri(path, query string) string {
+	return (&url.URL{Scheme: "file", Path: path, RawQuery: query}).String()
+}
+
+func (w *Writer) init() error {
+	if _, err := w.db.Exec(schema); err != nil {
+		return err
+	}
+	var stored string
+	err := w.db.QueryRow(`SELECT value FROM meta WHERE key = 'coords'`).Scan(&stored)
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := w.db.Exec(`INSERT INTO meta (key, value) VALUES ('coords', ?)`, w.coords.String()); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case stored != w.coords.String():
+		return fmt.Errorf("database holds %s positions, not %s", stored, w.coords)
+	}
+	if w.tx, err = w.db.Begin(); err != nil {
+		return err
+	}
+	w.site, err = w.tx.Prepare(`INSERT INTO sites (
+		tool, mirna_id, mirna_seq, target_id, start, end, strand, cleavage, score,
+		mirna_aligned, target_aligned, match, site, upstream, downstream,
+		upstream_pad, downstream_pad, chrom, genome_start, genome_end, genome_strand,
+		source_file, source_line
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	w.feature, err = w.tx.Prepare(`INSERT INTO features (site_id, name, value) VALUES (?, ?, ?)`)
+	return err
+}
+
+// ToolTable returns the name of the table holding a tool's records.
+func ToolTable(tool string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, tool)
+}
+
+// quote quotes an SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Add inserts the site, its features and its raw record. The first site
+// of a tool and source file removes the sites stored for them before.
+func (w *Writer) Add(s predict.TargetSite) error {
+	if err := w.replace(s.Tool, s.Origin.File); err != nil {
+		return err
+	}
+	start, end := w.coords.FromInternal(s.Start, s.End)
+	var score, cleavage, chrom, gStart, gEnd, gStrand any
+	if s.Scored() {
+		score = s.Score
+	}
+	if s.Cleavage >= 0 {
+		cleavage, _ = w.coords.FromInternal(s.Cleavage, s.Cleavage+1)
+	}
+	if g := s.Genome; g != nil {
+		chrom, gStrand = g.Chrom, g.Strand
+		gStart, gEnd = w.coords.FromInternal(g.Start, g.End)
+	}
+	res, err := w.site.Exec(
+		s.Tool, s.MiRNAID, s.MiRNASeq, s.TargetID, start, end, s.Strand, cleavage, score,
+		s.MiRNAAligned, s.TargetAligned, s.Match, s.Sequence, s.Upstream, s.Downstream,
+		s.UpstreamPad, s.DownstreamPad, chrom, gStart, gEnd, gStrand,
+		s.Origin.File, s.Origin.Line,
+	)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for name, v := range s.Features {
+		if _, err := w.feature.Exec(id, name, v); err != nil {
+			return err
+		}
+	}
+	return w.addRaw(ToolTable(s.Tool), id, s.Raw)
+}
+
+// replace deletes the sites, features and tool table rows an earlier
+// run stored for the tool and source file, once per Writer.
+func (w *Writer) replace(tool, file string) error {
+	key := [2]string{tool, file}
+	if w.replaced[key] {
+		return nil
+	}
+	w.replaced[key] = true
+	table := ToolTable(tool)
+	if _, err := w.columns(table); err != nil {
+		return err
+	}
+	ids := `SELECT id FROM sites WHERE tool = ? AND source_file = ?`
+	for _, del := range []string{
+		`DELETE FROM features WHERE site_id IN (` + ids + `)`,
+		fmt.Sprintf(`DELETE FROM %s WHERE site_id IN (%s)`, quote(table), ids),
+		`DELETE FROM sites WHERE tool = ? AND source_file = ?`,
+	} {
+		if _, err := w.tx.Exec(del, tool, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns returns the lower cased columns of the tool table, creating
+// the table on first use.
+func (w *Writer) columns(table string) (map[string]bool, error) {
+	cols, ok := w.tables[table]
+	if !ok {
+		cols = map[string]bool{}
+		create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (site_id INTEGER PRIMARY KEY REFERENCES sites(id))`, quote(table))
+		if _, err := w.tx.Exec(create); err != nil {
+			return nil, err
+		}
+		rows, err := w.tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			cols[strings.ToLower(name)] = true
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+		w.tables[table] = cols
+	}
+	return cols, nil
+}
+
+// addRaw stores the raw fields in the tool table, adding columns for
+// field names it has not seen yet.
+func (w *Writer) addRaw(table string, id int64, raw map[string]string) error {
+	cols, err := w.columns(table)
+	if err != nil {
+		return err
+	}
+
+	// Column names are case insensitive; of fields differing only in
+	// case the first in sorted order is kept.
+	sorted := make([]string, 0, len(raw))
+	for k := range raw {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	keys := make([]string, 0, len(raw))
+	seen := map[string]bool{"site_id": true}
+	for _, k := range sorted {
+		if seen[strings.ToLower(k)] {
+			continue
+		}
+		seen[strings.ToLower(k)] = true
+		keys = append(keys, k)
+		if !cols[strings.ToLower(k)] {
+			alter := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s TEXT`, quote(table), quote(k))
+			if _, err := w.tx.Exec(alter); err != nil {
+				return err
+			}
+			cols[strings.ToLower(k)] = true
+		}
+	}
+
+	stmtKey := table + "\x00" + strings.Join(keys, "\x00")
+	stmt, ok := w.toolRows[stmtKey]
+	if !ok {
+		names := []string{"site_id"}
+		for _, k := range keys {
+			names = append(names, quote(k))
+		}
+		insert := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?%s)`,
+			quote(table), strings.Join(names, ", "), strings.Repeat(", ?", len(keys)))
+		if stmt, err = w.tx.Prepare(insert); err != nil {
+			return err
+		}
+		w.toolRows[stmtKey] = stmt
+	}
+	args := []any{id}
+	for _, k := range keys {
+		args = append(args, raw[k])
+	}
+	_, err = stmt.Exec(args...)
+	return err
+}
+
+// Close indexes the tables, commits and closes the database.
+func (w *Writer) Close() error {
+	_, err := w.tx.Exec(indexes)
+	if err == nil {
+		err = w.tx.Commit()
+	} else {
+		w.tx.Rollback()
+	}
+	if cerr := w.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
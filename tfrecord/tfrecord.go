@@ -0,0 +1,140 @@
+// Package tfrecord writes TFRecord files of tf.train.Example records
+// without depending on TensorFlow or a protobuf library.
+package tfrecord
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC is the CRC32C checksum masked the way TFRecord stores it.
+func maskedCRC(b []byte) uint32 {
+	crc := crc32.Checksum(b, castagnoli)
+	return (crc>>15 | crc<<17) + 0xa282ead8
+}
+
+// Writer frames records as
+//
+//	uint64 length, uint32 masked crc of length, data, uint32 masked crc of data
+//
+// all little endian.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes one record.
+func (tw *Writer) Write(data []byte) error {
+	var head [12]byte
+	binary.LittleEndian.PutUint64(head[:8], uint64(len(data)))
+	binary.LittleEndian.PutUint32(head[8:], maskedCRC(head[:8]))
+	var tail [4]byte
+	binary.LittleEndian.PutUint32(tail[:], maskedCRC(data))
+	for _, b := range [][]byte{head[:], data, tail[:]} {
+		if _, err := tw.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Feature is one value list of a tf.train.Example. Only the list that
+// matches the constructor used is set.
+type Feature struct {
+	bytes  [][]byte
+	floats []float32
+	ints   []int64
+	kind   int
+}
+
+const (
+	bytesList = 1
+	floatList = 2
+	int64List = 3
+)
+
+// Bytes returns a bytes_list feature.
+func Bytes(v ...[]byte) Feature { return Feature{bytes: v, kind: bytesList} }
+
+// Strings returns a bytes_list feature of the strings.
+func Strings(v ...string) Feature {
+	b := make([][]byte, len(v))
+	for i, s := range v {
+		b[i] = []byte(s)
+	}
+	return Bytes(b...)
+}
+
+// Floats returns a float_list feature.
+func Floats(v ...float32) Feature { return Feature{floats: v, kind: floatList} }
+
+// Int64s returns an int64_list feature.
+func Int64s(v ...int64) Feature { return Feature{ints: v, kind: int64List} }
+
+// Example is the feature map of a tf.train.Example.
+type Example map[string]Feature
+
+// Marshal encodes the example in protobuf wire format. Keys are written
+// in sorted order so equal examples encode to equal bytes.
+func (e Example) Marshal() []byte {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var features []byte
+	for _, k := range keys {
+		var entry []byte
+		entry = appendBytes(entry, 1, []byte(k))
+		entry = appendBytes(entry, 2, e[k].marshal())
+		features = appendBytes(features, 1, entry)
+	}
+	// Example.features = 1, Features.feature = 1.
+	return appendBytes(nil, 1, features)
+}
+
+func (f Feature) marshal() []byte {
+	if f.kind == 0 {
+		return nil
+	}
+	var list []byte
+	switch f.kind {
+	case bytesList:
+		for _, b := range f.bytes {
+			list = appendBytes(list, 1, b)
+		}
+	case floatList:
+		packed := make([]byte, 0, 4*len(f.floats))
+		for _, v := range f.floats {
+			packed = binary.LittleEndian.AppendUint32(packed, math.Float32bits(v))
+		}
+		if len(packed) > 0 {
+			list = appendBytes(list, 1, packed)
+		}
+	case int64List:
+		var packed []byte
+		for _, v := range f.ints {
+			packed = binary.AppendUvarint(packed, uint64(v))
+		}
+		if len(packed) > 0 {
+			list = appendBytes(list, 1, packed)
+		}
+	}
+	return appendBytes(nil, f.kind, list)
+}
+
+// appendBytes appends a length delimited field.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,122 @@
+package tfrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestMaskedCRC(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint32
+	}{
+		// The CRC32C check value of "123456789" is 0xe3069283.
+		{"123456789", 0xc78ab0e5},
+		{"", 0xa282ead8},
+	}
+	for _, tt := range tests {
+		if got := maskedCRC([]byte(tt.in)); got != tt.want {
+			t.Errorf("maskedCRC(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	want := "0500000000000000" + "eab2043e" + hex.EncodeToString([]byte("hello")) + "bb1f1c19"
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
+
+// readRecords splits a TFRecord stream back into records, checking both
+// checksums of every frame.
+func readRecords(t *testing.T, r io.Reader) [][]byte {
+	t.Helper()
+	var records [][]byte
+	for {
+		var head [12]byte
+		if _, err := io.ReadFull(r, head[:]); err == io.EOF {
+			return records
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if got := binary.LittleEndian.Uint32(head[8:]); got != maskedCRC(head[:8]) {
+			t.Fatalf("record %d: length crc %#x", len(records), got)
+		}
+		data := make([]byte, binary.LittleEndian.Uint64(head[:8])+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			t.Fatal(err)
+		}
+		n := len(data) - 4
+		if got := binary.LittleEndian.Uint32(data[n:]); got != maskedCRC(data[:n]) {
+			t.Fatalf("record %d: data crc %#x", len(records), got)
+		}
+		records = append(records, data[:n])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := [][]byte{[]byte("first"), {}, bytes.Repeat([]byte{0xff}, 1000)}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, rec := range in {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := readRecords(t, &buf)
+	if len(out) != len(in) {
+		t.Fatalf("read %d records, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if !bytes.Equal(out[i], in[i]) {
+			t.Errorf("record %d: got %x, want %x", i, out[i], in[i])
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		ex   Example
+		want string
+	}{
+		{"empty", Example{}, "0a00"},
+		{
+			"int64",
+			Example{"a": Int64s(1, 300)},
+			// Example{features{feature{key "a", value{int64_list{packed 1, 300}}}}}
+			"0a0e" + "0a0c" + "0a0161" + "1207" + "1a05" + "0a03" + "01ac02",
+		},
+		{
+			// Keys are sorted, negative ints take ten varint bytes and
+			// an empty list is an empty message.
+			"mixed",
+			Example{"s": Strings("x"), "f": Floats(1.5), "e": Int64s(), "a": Int64s(1, 300, -1)},
+			"0a3c" +
+				"0a16" + "0a0161" + "1211" + "1a0f" + "0a0d" + "01ac02" + "ffffffffffffffffff01" +
+				"0a07" + "0a0165" + "1202" + "1a00" +
+				"0a0d" + "0a0166" + "1208" + "1206" + "0a04" + "0000c03f" +
+				"0a0a" + "0a0173" + "1205" + "0a03" + "0a0178",
+		},
+		{
+			"bytes",
+			Example{"b": Bytes([]byte{0, 1}, nil)},
+			"0a0f" + "0a0d" + "0a0162" + "1208" + "0a06" + "0a020001" + "0a00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.ex.Marshal()); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
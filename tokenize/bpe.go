@@ -0,0 +1,462 @@
+package tokenize
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Bases are the initial BPE symbols after the special tokens.
+var Bases = []string{"A", "C", "G", "T"}
+
+// BPE is a byte-pair-encoding tokenizer over nucleotides. Merges are
+// applied lowest rank first, which gives the same tokens as the
+// HuggingFace tokenizers BPE model saved by WriteJSON.
+type BPE struct {
+	vocab  *Vocab
+	merges [][2]string
+	ranks  map[[2]int]int
+}
+
+// TrainOptions control BPE training.
+type TrainOptions struct {
+	// VocabSize is the size the vocabulary grows to, specials included.
+	VocabSize int
+	// MinFrequency stops training once the most frequent pair occurs
+	// fewer times.
+	MinFrequency int
+	// WordLength is the length of the chunks sequences are cut into for
+	// counting; no token grows longer.
+	WordLength int
+}
+
+// DefaultTrainOptions are the settings used by the bpe command.
+var DefaultTrainOptions = TrainOptions{VocabSize: 4096, MinFrequency: 2, WordLength: 64}
+
+// Trainer counts the chunks of training sequences until Train is called.
+type Trainer struct {
+	opt    TrainOptions
+	counts map[string]int
+}
+
+// NewTrainer returns a Trainer with the given options.
+func NewTrainer(opt TrainOptions) (*Trainer, error) {
+	if opt.VocabSize < len(Specials)+len(Bases) {
+		return nil, fmt.Errorf("BPE vocabulary size %d is below the %d special and base tokens", opt.VocabSize, len(Specials)+len(Bases))
+	}
+	if opt.WordLength < 2 {
+		return nil, fmt.Errorf("BPE word length %d is below 2", opt.WordLength)
+	}
+	return &Trainer{opt: opt, counts: map[string]int{}}, nil
+}
+
+// Add counts the chunks of seq. Bases other than A, C, G and T (after
+// folding case and reading U as T) break the sequence, so no merge
+// learns them.
+func (tr *Trainer) Add(seq string) {
+	seq = strings.ReplaceAll(strings.ToUpper(seq), "U", "T")
+	for _, run := range strings.FieldsFunc(seq, func(r rune) bool { return !strings.ContainsRune("ACGT", r) }) {
+		for i := 0; i < len(run); i += tr.opt.WordLength {
+			tr.counts[run[i:min(i+tr.opt.WordLength, len(run))]]++
+		}
+	}
+}
+
+type trainWord struct {
+	syms  []int32
+	count int
+}
+
+// pairEntry is a candidate merge with the count it had when pushed.
+// Entries go stale as counts change and are skipped when popped.
+type pairEntry struct {
+	pair  [2]int32
+	count int
+}
+
+// pairHeap orders candidate pairs by count, then by symbol IDs so ties
+// break the same way on every run.
+type pairHeap []pairEntry
+
+func (h pairHeap) Len() int { return len(h) }
+func (h pairHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count > h[j].count
+	}
+	if h[i].pair[0] != h[j].pair[0] {
+		return h[i].pair[0] < h[j].pair[0]
+	}
+	return h[i].pair[1] < h[j].pair[1]
+}
+func (h pairHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pairHeap) Push(x any)   { *h = append(*h, x.(pairEntry)) }
+func (h *pairHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Train learns merges from the counted chunks.
+func (tr *Trainer) Train() *BPE {
+	tokens := append(append([]string(nil), Specials...), Bases...)
+	ids := map[string]int32{}
+	for i, t := range tokens {
+		ids[t] = int32(i)
+	}
+
+	// Words are visited in sorted order so symbol IDs, and with them
+	// tie breaks, do not depend on map order.
+	chunks := make([]string, 0, len(tr.counts))
+	for c := range tr.counts {
+		chunks = append(chunks, c)
+	}
+	sort.Strings(chunks)
+	words := make([]trainWord, len(chunks))
+	pairs := map[[2]int32]int{}
+	where := map[[2]int32]map[int]bool{}
+	addPairs := func(w int, sign int) {
+		syms := words[w].syms
+		for i := 0; i+1 < len(syms); i++ {
+			p := [2]int32{syms[i], syms[i+1]}
+			pairs[p] += sign * words[w].count
+			if sign > 0 {
+				if where[p] == nil {
+					where[p] = map[int]bool{}
+				}
+				where[p][w] = true
+			}
+		}
+	}
+	for w, c := range chunks {
+		syms := make([]int32, len(c))
+		for i := 0; i < len(c); i++ {
+			syms[i] = ids[c[i:i+1]]
+		}
+		words[w] = trainWord{syms: syms, count: tr.counts[c]}
+		addPairs(w, 1)
+	}
+	h := make(pairHeap, 0, len(pairs))
+	for p, c := range pairs {
+		h = append(h, pairEntry{p, c})
+	}
+	heap.Init(&h)
+
+	var merges [][2]string
+	for len(tokens) < tr.opt.VocabSize && h.Len() > 0 {
+		best := heap.Pop(&h).(pairEntry)
+		if pairs[best.pair] != best.count || best.count == 0 {
+			continue
+		}
+		if best.count < tr.opt.MinFrequency {
+			break
+		}
+		left, right := tokens[best.pair[0]], tokens[best.pair[1]]
+		merged := left + right
+		id, ok := ids[merged]
+		if !ok {
+			id = int32(len(tokens))
+			ids[merged] = id
+			tokens = append(tokens, merged)
+		}
+		merges = append(merges, [2]string{left, right})
+
+		touched := map[[2]int32]bool{}
+		for w := range where[best.pair] {
+			addPairs(w, -1)
+			for i := 0; i+1 < len(words[w].syms); i++ {
+				touched[[2]int32{words[w].syms[i], words[w].syms[i+1]}] = true
+			}
+			words[w].syms = mergeSyms(words[w].syms, best.pair, id)
+			addPairs(w, 1)
+			for i := 0; i+1 < len(words[w].syms); i++ {
+				touched[[2]int32{words[w].syms[i], words[w].syms[i+1]}] = true
+			}
+		}
+		delete(where, best.pair)
+		delete(pairs, best.pair)
+		for p := range touched {
+			if c, ok := pairs[p]; ok && c > 0 && p != best.pair {
+				heap.Push(&h, pairEntry{p, c})
+			}
+		}
+	}
+	return newBPE(tokens, merges)
+}
+
+// mergeSyms replaces every occurrence of pair in syms, left to right,
+// by id.
+func mergeSyms(syms []int32, pair [2]int32, id int32) []int32 {
+	out := syms[:0]
+	for i := 0; i < len(syms); i++ {
+		if i+1 < len(syms) && syms[i] == pair[0] && syms[i+1] == pair[1] {
+			out = append(out, id)
+			i++
+			continue
+		}
+		out = append(out, syms[i])
+	}
+	return out
+}
+
+func newBPE(tokens []string, merges [][2]string) *BPE {
+	b := &BPE{vocab: NewVocab(tokens), merges: merges, ranks: map[[2]int]int{}}
+	for rank, m := range merges {
+		p := [2]int{b.vocab.ID(m[0]), b.vocab.ID(m[1])}
+		if _, ok := b.ranks[p]; !ok {
+			b.ranks[p] = rank
+		}
+	}
+	return b
+}
+
+// Vocab returns the tokenizer's vocabulary.
+func (b *BPE) Vocab() *Vocab { return b.vocab }
+
+// Merges returns the learned merges in rank order.
+func (b *BPE) Merges() [][2]string { return b.merges }
+
+// Tokenize splits seq into BPE tokens. Case is folded and U read as T;
+// bases outside the vocabulary become one [UNK] each.
+func (b *BPE) Tokenize(seq string) []string {
+	seq = strings.ReplaceAll(strings.ToUpper(seq), "U", "T")
+	syms := make([]int, len(seq))
+	for i := 0; i < len(seq); i++ {
+		syms[i] = b.vocab.ID(seq[i : i+1])
+	}
+	for len(syms) > 1 {
+		bestRank, bestAt := -1, -1
+		for i := 0; i+1 < len(syms); i++ {
+			if r, ok := b.ranks[[2]int{syms[i], syms[i+1]}]; ok && (bestRank < 0 || r < bestRank) {
+				bestRank, bestAt = r, i
+			}
+		}
+		if bestAt < 0 {
+			break
+		}
+		pair := [2]int{syms[bestAt], syms[bestAt+1]}
+		merged := b.vocab.ID(b.vocab.tokens[pair[0]] + b.vocab.tokens[pair[1]])
+		out := syms[:0]
+		for i := 0; i < len(syms); i++ {
+			if i+1 < len(syms) && syms[i] == pair[0] && syms[i+1] == pair[1] {
+				out = append(out, merged)
+				i++
+				continue
+			}
+			out = append(out, syms[i])
+		}
+		syms = out
+	}
+	tokens := make([]string, len(syms))
+	for i, id := range syms {
+		tokens[i] = b.vocab.tokens[id]
+	}
+	return tokens
+}
+
+// Decode turns IDs back into sequence, split at [SEP] into the parts
+// Encode joined. [CLS], [PAD] and [MASK] are dropped and [UNK] reads as
+// N.
+func Decode(v *Vocab, ids []int) ([]string, error) {
+	var (
+		parts []string
+		cur   strings.Builder
+	)
+	for _, id := range ids {
+		tok, err := v.Token(id)
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case CLS, PAD, MASK:
+		case SEP:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		case UNK:
+			cur.WriteByte('N')
+		default:
+			cur.WriteString(tok)
+		}
+	}
+	if cur.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts, nil
+}
+
+// hfAddedToken is an entry of added_tokens in tokenizer.json.
+type hfAddedToken struct {
+	ID         int    `json:"id"`
+	Content    string `json:"content"`
+	SingleWord bool   `json:"single_word"`
+	Lstrip     bool   `json:"lstrip"`
+	Rstrip     bool   `json:"rstrip"`
+	Normalized bool   `json:"normalized"`
+	Special    bool   `json:"special"`
+}
+
+// hfTemplate is a TemplateProcessing post_processor in tokenizer.json.
+type hfTemplate struct {
+	Type          string                     `json:"type"`
+	Single        []hfPiece                  `json:"single"`
+	Pair          []hfPiece                  `json:"pair"`
+	SpecialTokens map[string]hfTemplateToken `json:"special_tokens"`
+}
+
+// hfPiece is one element of a template: a special token or one of the
+// input sequences, A or B.
+type hfPiece struct {
+	SpecialToken *hfPieceID `json:"SpecialToken,omitempty"`
+	Sequence     *hfPieceID `json:"Sequence,omitempty"`
+}
+
+type hfPieceID struct {
+	ID     string `json:"id"`
+	TypeID int    `json:"type_id"`
+}
+
+type hfTemplateToken struct {
+	ID     string   `json:"id"`
+	IDs    []int    `json:"ids"`
+	Tokens []string `json:"tokens"`
+}
+
+// template returns the post-processor that adds the special tokens of
+// Encode: [CLS] A [SEP] for one sequence and [CLS] A [SEP] B [SEP] for a
+// pair, with B and its [SEP] in segment 1. Templates take at most two
+// sequences, so the downstream part of Encode has no equivalent.
+func template(v *Vocab) hfTemplate {
+	special := func(tok string, seg int) hfPiece {
+		return hfPiece{SpecialToken: &hfPieceID{ID: tok, TypeID: seg}}
+	}
+	sequence := func(id string, seg int) hfPiece {
+		return hfPiece{Sequence: &hfPieceID{ID: id, TypeID: seg}}
+	}
+	t := hfTemplate{
+		Type:          "TemplateProcessing",
+		Single:        []hfPiece{special(CLS, 0), sequence("A", 0), special(SEP, 0)},
+		Pair:          []hfPiece{special(CLS, 0), sequence("A", 0), special(SEP, 0), sequence("B", 1), special(SEP, 1)},
+		SpecialTokens: map[string]hfTemplateToken{},
+	}
+	for _, tok := range []string{CLS, SEP} {
+		t.SpecialTokens[tok] = hfTemplateToken{ID: tok, IDs: []int{v.ID(tok)}, Tokens: []string{tok}}
+	}
+	return t
+}
+
+// orderedVocab marshals as a JSON object in ID order.
+type orderedVocab []string
+
+func (o orderedVocab) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, t := range o {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		key, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&sb, "%s:%d", key, i)
+	}
+	sb.WriteByte('}')
+	return []byte(sb.String()), nil
+}
+
+type hfModel struct {
+	Type                    string          `json:"type"`
+	Dropout                 *float64        `json:"dropout"`
+	UnkToken                string          `json:"unk_token"`
+	ContinuingSubwordPrefix *string         `json:"continuing_subword_prefix"`
+	EndOfWordSuffix         *string         `json:"end_of_word_suffix"`
+	FuseUnk                 bool            `json:"fuse_unk"`
+	ByteFallback            bool            `json:"byte_fallback"`
+	Vocab                   json.RawMessage `json:"vocab"`
+	Merges                  []string        `json:"merges"`
+}
+
+type hfTokenizer struct {
+	Version       string          `json:"version"`
+	Truncation    any             `json:"truncation"`
+	Padding       any             `json:"padding"`
+	AddedTokens   []hfAddedToken  `json:"added_tokens"`
+	Normalizer    any             `json:"normalizer"`
+	PreTokenizer  any             `json:"pre_tokenizer"`
+	PostProcessor any             `json:"post_processor"`
+	Decoder       any             `json:"decoder"`
+	Model         json.RawMessage `json:"model"`
+}
+
+// WriteJSON saves the tokenizer as a HuggingFace tokenizers JSON file,
+// loadable with tokenizers.Tokenizer.from_file or
+// PreTrainedTokenizerFast(tokenizer_file=...). Its post-processor adds
+// [CLS] and [SEP] as Encode does for one sequence or a pair; a site with
+// both flanks is encoded the same way only by Encode itself.
+func (b *BPE) WriteJSON(w io.Writer) error {
+	vocab, err := orderedVocab(b.vocab.tokens).MarshalJSON()
+	if err != nil {
+		return err
+	}
+	merges := make([]string, len(b.merges))
+	for i, m := range b.merges {
+		merges[i] = m[0] + " " + m[1]
+	}
+	model, err := json.Marshal(hfModel{Type: "BPE", UnkToken: UNK, Vocab: vocab, Merges: merges})
+	if err != nil {
+		return err
+	}
+	tok := hfTokenizer{Version: "1.0", PostProcessor: template(b.vocab), Model: model}
+	for i, s := range Specials {
+		tok.AddedTokens = append(tok.AddedTokens, hfAddedToken{ID: i, Content: s, Special: true})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tok)
+}
+
+// ReadJSON loads a BPE tokenizer saved by WriteJSON. Merges may be
+// written as "a b" strings or as [a, b] pairs.
+func ReadJSON(r io.Reader) (*BPE, error) {
+	var tok hfTokenizer
+	if err := json.NewDecoder(r).Decode(&tok); err != nil {
+		return nil, err
+	}
+	var model struct {
+		Type   string            `json:"type"`
+		Vocab  map[string]int    `json:"vocab"`
+		Merges []json.RawMessage `json:"merges"`
+	}
+	if err := json.Unmarshal(tok.Model, &model); err != nil {
+		return nil, fmt.Errorf("tokenizer model: %w", err)
+	}
+	if model.Type != "BPE" {
+		return nil, fmt.Errorf("tokenizer model is %q, not BPE", model.Type)
+	}
+	tokens := make([]string, len(model.Vocab))
+	for t, id := range model.Vocab {
+		if id < 0 || id >= len(tokens) || tokens[id] != "" {
+			return nil, fmt.Errorf("tokenizer vocabulary IDs are not 0-%d", len(tokens)-1)
+		}
+		tokens[id] = t
+	}
+	merges := make([][2]string, len(model.Merges))
+	for i, raw := range model.Merges {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			left, right, ok := strings.Cut(s, " ")
+			if !ok {
+				return nil, fmt.Errorf("tokenizer merge %q is not two tokens", s)
+			}
+			merges[i] = [2]string{left, right}
+			continue
+		}
+		if err := json.Unmarshal(raw, &merges[i]); err != nil {
+			return nil, fmt.Errorf("tokenizer merge %d: %w", i, err)
+		}
+	}
+	return newBPE(tokens, merges), nil
+}
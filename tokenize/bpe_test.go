@@ -0,0 +1,222 @@
+package tokenize
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func train(t *testing.T, opt TrainOptions, seqs ...string) *BPE {
+	t.Helper()
+	tr, err := NewTrainer(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range seqs {
+		tr.Add(s)
+	}
+	return tr.Train()
+}
+
+// randomSeqs returns n sequences of 200 bases.
+func randomSeqs(seed int64, n int) []string {
+	rng := rand.New(rand.NewSource(seed))
+	seqs := make([]string, n)
+	for i := range seqs {
+		b := make([]byte, 200)
+		for j := range b {
+			b[j] = "ACGT"[rng.Intn(4)]
+		}
+		seqs[i] = string(b)
+	}
+	return seqs
+}
+
+func TestTrain(t *testing.T) {
+	tests := []struct {
+		name   string
+		seqs   []string
+		size   int
+		merges [][2]string
+	}{
+		// AC, CG and GT tie at two; the lowest symbol IDs win, so AC
+		// goes first and then GT, whose G precedes the new AC.
+		{"ties", []string{"ACGTACGT"}, 12, [][2]string{{"A", "C"}, {"G", "T"}, {"AC", "GT"}}},
+		{"repeat", []string{"AAAA"}, 11, [][2]string{{"A", "A"}, {"AA", "AA"}}},
+		{"vocab size", []string{"ACGTACGT"}, 10, [][2]string{{"A", "C"}}},
+		{"ambiguous bases split", []string{"ANA", "cnc"}, 12, nil},
+		{"rna", []string{"ACGUACGU"}, 12, [][2]string{{"A", "C"}, {"G", "T"}, {"AC", "GT"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := train(t, TrainOptions{VocabSize: tt.size, MinFrequency: 1, WordLength: 64}, tt.seqs...)
+			if !reflect.DeepEqual(b.Merges(), tt.merges) {
+				t.Errorf("got merges %v, want %v", b.Merges(), tt.merges)
+			}
+			if want := len(Specials) + len(Bases) + len(tt.merges); b.Vocab().Len() != want {
+				t.Errorf("got %d tokens, want %d", b.Vocab().Len(), want)
+			}
+		})
+	}
+}
+
+func TestTrainMinFrequency(t *testing.T) {
+	b := train(t, TrainOptions{VocabSize: 100, MinFrequency: 2, WordLength: 64}, "ACGTAC")
+	if want := [][2]string{{"A", "C"}}; !reflect.DeepEqual(b.Merges(), want) {
+		t.Errorf("got merges %v, want %v", b.Merges(), want)
+	}
+}
+
+func TestTrainDeterministic(t *testing.T) {
+	opt := TrainOptions{VocabSize: 200, MinFrequency: 2, WordLength: 16}
+	seqs := randomSeqs(1, 50)
+	reversed := make([]string, len(seqs))
+	for i, s := range seqs {
+		reversed[len(seqs)-1-i] = s
+	}
+	var first bytes.Buffer
+	if err := train(t, opt, seqs...).WriteJSON(&first); err != nil {
+		t.Fatal(err)
+	}
+	for run, in := range [][]string{seqs, reversed, seqs} {
+		var buf bytes.Buffer
+		if err := train(t, opt, in...).WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), first.Bytes()) {
+			t.Fatalf("run %d trained a different tokenizer", run)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	b := train(t, TrainOptions{VocabSize: 12, MinFrequency: 1, WordLength: 64}, "ACGTACGT")
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"ACGTAC", []string{"ACGT", "AC"}},
+		{"acgu", []string{"ACGT"}},
+		{"ACNGT", []string{"AC", UNK, "GT"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		if got := b.Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	b := train(t, TrainOptions{VocabSize: 100, MinFrequency: 2, WordLength: 16}, randomSeqs(2, 20)...)
+	var buf bytes.Buffer
+	if err := b.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadJSON(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Vocab().Tokens(), b.Vocab().Tokens()) || !reflect.DeepEqual(loaded.Merges(), b.Merges()) {
+		t.Fatal("vocabulary or merges changed on reload")
+	}
+	for _, s := range randomSeqs(3, 5) {
+		if got, want := loaded.Tokenize(s), b.Tokenize(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("reloaded tokenizer gives %v, want %v", got, want)
+		}
+	}
+
+	// Newer tokenizers versions write merges as pairs.
+	pairs := `{"model": {"type": "BPE", "vocab": {"A": 0, "C": 1, "AC": 2}, "merges": [["A", "C"]]}}`
+	loaded, err = ReadJSON(strings.NewReader(pairs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Tokenize("ACA"); !reflect.DeepEqual(got, []string{"AC", "A"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	b := train(t, TrainOptions{VocabSize: 100, MinFrequency: 2, WordLength: 16}, randomSeqs(4, 20)...)
+	tests := []struct {
+		up, site, down string
+	}{
+		{"ACGTTGCA", "TGACAGAAGAGAGTGAGCAC", "GGGCCCAAATTT"},
+		{"", "ACGT", ""},
+		{"acgu", "ACNNT", "T"},
+	}
+	for _, tt := range tests {
+		e := Encode(b, tt.up, tt.site, tt.down)
+		parts, err := Decode(b.Vocab(), e.IDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for _, p := range []string{tt.up, tt.site, tt.down} {
+			want = append(want, strings.ReplaceAll(strings.ToUpper(p), "U", "T"))
+		}
+		if !reflect.DeepEqual(parts, want) {
+			t.Errorf("Decode(Encode(%q, %q, %q)) = %q", tt.up, tt.site, tt.down, parts)
+		}
+		if len(e.Segments) != len(e.IDs) || e.Segments[len(e.Segments)-1] != SegmentDownstream {
+			t.Errorf("got segments %v", e.Segments)
+		}
+	}
+	if _, err := Decode(b.Vocab(), []int{b.Vocab().Len()}); err == nil {
+		t.Error("decoding an ID past the vocabulary succeeded")
+	}
+}
+
+// TestPostProcessor applies the saved template the way the tokenizers
+// library does and compares the result with Encode.
+func TestPostProcessor(t *testing.T) {
+	b := train(t, TrainOptions{VocabSize: 100, MinFrequency: 2, WordLength: 16}, randomSeqs(5, 20)...)
+	var buf bytes.Buffer
+	if err := b.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var tok struct {
+		PostProcessor hfTemplate `json:"post_processor"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &tok); err != nil {
+		t.Fatal(err)
+	}
+	pp := tok.PostProcessor
+	if pp.Type != "TemplateProcessing" {
+		t.Fatalf("post_processor is %q", pp.Type)
+	}
+	up, site := "ACGTTGCAAGT", "TGACAGAAGAGAGTGAGCAC"
+	apply := func(pieces []hfPiece) (ids, types []int) {
+		for _, p := range pieces {
+			switch {
+			case p.SpecialToken != nil:
+				for _, id := range pp.SpecialTokens[p.SpecialToken.ID].IDs {
+					ids = append(ids, id)
+					types = append(types, p.SpecialToken.TypeID)
+				}
+			case p.Sequence != nil:
+				seq := map[string]string{"A": up, "B": site}[p.Sequence.ID]
+				for _, tok := range b.Tokenize(seq) {
+					ids = append(ids, b.Vocab().ID(tok))
+					types = append(types, p.Sequence.TypeID)
+				}
+			}
+		}
+		return ids, types
+	}
+	// A pair is Encode without the empty downstream part and its [SEP].
+	e := Encode(b, up, site, "")
+	ids, types := apply(pp.Pair)
+	if n := len(e.IDs) - 1; !reflect.DeepEqual(ids, e.IDs[:n]) || !reflect.DeepEqual(types, e.Segments[:n]) {
+		t.Errorf("pair gives %v %v, want %v %v", ids, types, e.IDs[:n], e.Segments[:n])
+	}
+	e = Encode(b, up, "", "")
+	ids, types = apply(pp.Single)
+	if n := len(e.IDs) - 2; !reflect.DeepEqual(ids, e.IDs[:n]) || !reflect.DeepEqual(types, e.Segments[:n]) {
+		t.Errorf("single gives %v %v, want %v %v", ids, types, e.IDs[:n], e.Segments[:n])
+	}
+}
@@ -0,0 +1,60 @@
+package tokenize
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxK bounds k; the vocabulary holds all 4^k k-mers.
+const MaxK = 10
+
+// KMer splits sequences into overlapping k-mers, DNABERT style. A k-mer
+// starts every Stride bases, and windows running past the end of the
+// sequence are left out. K-mers holding anything but A, C, G and T
+// become [UNK].
+type KMer struct {
+	K      int
+	Stride int
+	vocab  *Vocab
+}
+
+// NewKMer returns a k-mer tokenizer whose vocabulary is the special
+// tokens followed by every k-mer over ACGT in lexical order.
+func NewKMer(k, stride int) (*KMer, error) {
+	if k < 1 || k > MaxK {
+		return nil, fmt.Errorf("k-mer size %d outside 1-%d", k, MaxK)
+	}
+	if stride < 1 {
+		return nil, fmt.Errorf("k-mer stride %d is not positive", stride)
+	}
+	tokens := append([]string(nil), Specials...)
+	kmers := []string{""}
+	for i := 0; i < k; i++ {
+		next := make([]string, 0, len(kmers)*4)
+		for _, p := range kmers {
+			for _, b := range "ACGT" {
+				next = append(next, p+string(b))
+			}
+		}
+		kmers = next
+	}
+	return &KMer{K: k, Stride: stride, vocab: NewVocab(append(tokens, kmers...))}, nil
+}
+
+// Vocab returns the tokenizer's vocabulary.
+func (t *KMer) Vocab() *Vocab { return t.vocab }
+
+// Tokenize returns the k-mers of seq. RNA input is read as DNA and case
+// is ignored.
+func (t *KMer) Tokenize(seq string) []string {
+	seq = strings.ReplaceAll(strings.ToUpper(seq), "U", "T")
+	var tokens []string
+	for i := 0; i+t.K <= len(seq); i += t.Stride {
+		kmer := seq[i : i+t.K]
+		if !t.vocab.Has(kmer) {
+			kmer = UNK
+		}
+		tokens = append(tokens, kmer)
+	}
+	return tokens
+}
@@ -0,0 +1,72 @@
+package tokenize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKMerVocab(t *testing.T) {
+	k, err := NewKMer(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DNABERT's vocab.txt lists the special tokens and then the k-mers.
+	want := []string{"[PAD]", "[UNK]", "[CLS]", "[SEP]", "[MASK]",
+		"AA", "AC", "AG", "AT", "CA", "CC", "CG", "CT",
+		"GA", "GC", "GG", "GT", "TA", "TC", "TG", "TT"}
+	if got := k.Vocab().Tokens(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got vocabulary %v, want %v", got, want)
+	}
+	if k, err = NewKMer(6, 1); err != nil {
+		t.Fatal(err)
+	}
+	tokens := k.Vocab().Tokens()
+	if len(tokens) != 5+4096 || tokens[5] != "AAAAAA" || tokens[6] != "AAAAAC" || tokens[len(tokens)-1] != "TTTTTT" {
+		t.Errorf("6-mer vocabulary has %d tokens, %v ... %s", len(tokens), tokens[:7], tokens[len(tokens)-1])
+	}
+}
+
+func TestKMerTokenize(t *testing.T) {
+	tests := []struct {
+		name      string
+		k, stride int
+		in        string
+		want      []string
+	}{
+		{"overlapping", 3, 1, "ACGTA", []string{"ACG", "CGT", "GTA"}},
+		// The last window would run past the end and is left out.
+		{"stride 2", 3, 2, "ACGTAC", []string{"ACG", "GTA"}},
+		{"stride k", 3, 3, "ACGTAC", []string{"ACG", "TAC"}},
+		{"shorter than k", 3, 1, "AC", nil},
+		{"rna and lowercase", 3, 1, "acgu", []string{"ACG", "CGT"}},
+		{"ambiguous", 3, 1, "ACNGTA", []string{UNK, UNK, UNK, "GTA"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := NewKMer(tt.k, tt.stride)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := k.Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKMerErrors(t *testing.T) {
+	tests := []struct {
+		k, stride int
+		err       string
+	}{
+		{0, 1, "k-mer size 0"},
+		{MaxK + 1, 1, "k-mer size 11"},
+		{3, 0, "stride 0"},
+	}
+	for _, tt := range tests {
+		if _, err := NewKMer(tt.k, tt.stride); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("NewKMer(%d, %d) gives %v, want %q", tt.k, tt.stride, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package tokenize turns extracted site sequences into token IDs for
+// transformer models: a vocabulary with the BERT special tokens and
+// tokenizers splitting sequences into vocabulary entries.
+package tokenize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Special tokens. They take the first vocabulary IDs in this order, as
+// in DNABERT's vocab.txt.
+const (
+	PAD  = "[PAD]"
+	UNK  = "[UNK]"
+	CLS  = "[CLS]"
+	SEP  = "[SEP]"
+	MASK = "[MASK]"
+)
+
+// Specials lists the special tokens in ID order.
+var Specials = []string{PAD, UNK, CLS, SEP, MASK}
+
+// Tokenizer splits a sequence into tokens of its vocabulary.
+type Tokenizer interface {
+	Tokenize(seq string) []string
+	Vocab() *Vocab
+}
+
+// Vocab maps tokens to IDs, the ID being the position in the list.
+type Vocab struct {
+	tokens []string
+	ids    map[string]int
+}
+
+// NewVocab returns a vocabulary of the tokens in ID order. Repeated
+// tokens keep their first ID.
+func NewVocab(tokens []string) *Vocab {
+	v := &Vocab{tokens: tokens, ids: make(map[string]int, len(tokens))}
+	for i, t := range tokens {
+		if _, ok := v.ids[t]; !ok {
+			v.ids[t] = i
+		}
+	}
+	return v
+}
+
+// ReadVocab reads a vocab.txt file of one token per line.
+func ReadVocab(r io.Reader) (*Vocab, error) {
+	var tokens []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		tokens = append(tokens, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return NewVocab(tokens), nil
+}
+
+// Len is the number of tokens.
+func (v *Vocab) Len() int { return len(v.tokens) }
+
+// Tokens returns the tokens in ID order.
+func (v *Vocab) Tokens() []string { return v.tokens }
+
+// ID returns the ID of tok, or that of [UNK] when tok is not known.
+func (v *Vocab) ID(tok string) int {
+	if id, ok := v.ids[tok]; ok {
+		return id
+	}
+	return v.ids[UNK]
+}
+
+// Has reports whether tok is in the vocabulary.
+func (v *Vocab) Has(tok string) bool {
+	_, ok := v.ids[tok]
+	return ok
+}
+
+// Token returns the token with the given ID.
+func (v *Vocab) Token(id int) (string, error) {
+	if id < 0 || id >= len(v.tokens) {
+		return "", fmt.Errorf("token ID %d outside vocabulary of %d", id, len(v.tokens))
+	}
+	return v.tokens[id], nil
+}
+
+// WriteTo writes the vocabulary as vocab.txt, one token per line.
+func (v *Vocab) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, t := range v.tokens {
+		m, err := bw.WriteString(t + "\n")
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// Segment IDs of the three parts of an encoded site.
+const (
+	SegmentUpstream = iota
+	SegmentSite
+	SegmentDownstream
+)
+
+// Encoded is a tokenized site. Segments gives the part each token
+// belongs to; [CLS] counts to the upstream and each [SEP] to the part
+// it closes.
+type Encoded struct {
+	Tokens   []string
+	IDs      []int
+	Segments []int
+}
+
+// Encode tokenizes the parts of a site separately and joins them as
+//
+//	[CLS] upstream [SEP] site [SEP] downstream [SEP]
+//
+// so no token spans a flank boundary.
+func Encode(t Tokenizer, upstream, site, downstream string) Encoded {
+	var e Encoded
+	add := func(tok string, seg int) {
+		e.Tokens = append(e.Tokens, tok)
+		e.IDs = append(e.IDs, t.Vocab().ID(tok))
+		e.Segments = append(e.Segments, seg)
+	}
+	add(CLS, SegmentUpstream)
+	for seg, part := range []string{upstream, site, downstream} {
+		for _, tok := range t.Tokenize(part) {
+			add(tok, seg)
+		}
+		add(SEP, seg)
+	}
+	return e
+}
@@ -0,0 +1,77 @@
+// Package window cuts a site with its flanks to a fixed length for
+// models that need equal-length inputs, recording which positions hold
+// sequence and which part of the site each position comes from.
+package window
+
+import "strings"
+
+// Segment IDs, matching the token_type_ids of the tokenize package.
+const (
+	SegmentUpstream uint8 = iota
+	SegmentSite
+	SegmentDownstream
+)
+
+// PadBase fills window positions that lie beyond the available sequence.
+const PadBase = 'N'
+
+// Parts is a site with its flanks, read 5' to 3'. UpstreamPad and
+// DownstreamPad count pad characters already at the outer ends of the
+// flanks, which count as padding in the window too. Center is the
+// position in Upstream+Site+Downstream the window is centred on.
+type Parts struct {
+	Upstream      string
+	Site          string
+	Downstream    string
+	UpstreamPad   int
+	DownstreamPad int
+	Center        int
+}
+
+// Window is a fixed-length cut of Upstream+Site+Downstream. Position i
+// of the window is position Offset+i of that sequence; Offset is
+// negative when the window starts before it. Mask is 1 where Sequence
+// holds a real base and 0 for padding, and Segments gives the part each
+// position belongs to, padding counting to the flank it extends.
+type Window struct {
+	Sequence string
+	Mask     []uint8
+	Segments []uint8
+	Offset   int
+}
+
+// Cut returns the length positions centred on p.Center. The centre
+// position falls at index length/2, so a cut made before it lies in the
+// middle of an even length window.
+func Cut(p Parts, length int) Window {
+	full := p.Upstream + p.Site + p.Downstream
+	siteStart, siteEnd := len(p.Upstream), len(p.Upstream)+len(p.Site)
+	realStart, realEnd := p.UpstreamPad, len(full)-p.DownstreamPad
+
+	w := Window{
+		Mask:     make([]uint8, length),
+		Segments: make([]uint8, length),
+		Offset:   p.Center - length/2,
+	}
+	var seq strings.Builder
+	seq.Grow(length)
+	for i := 0; i < length; i++ {
+		j := w.Offset + i
+		switch {
+		case j < siteStart:
+			w.Segments[i] = SegmentUpstream
+		case j < siteEnd:
+			w.Segments[i] = SegmentSite
+		default:
+			w.Segments[i] = SegmentDownstream
+		}
+		if j >= realStart && j < realEnd {
+			seq.WriteByte(full[j])
+			w.Mask[i] = 1
+		} else {
+			seq.WriteByte(PadBase)
+		}
+	}
+	w.Sequence = seq.String()
+	return w
+}
@@ -0,0 +1,47 @@
+package window
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCut(t *testing.T) {
+	parts := Parts{Upstream: "AAAA", Site: "CCCC", Downstream: "GGGG"}
+	padded := Parts{Upstream: "--AA", Site: "CCCC", Downstream: "GG--", UpstreamPad: 2, DownstreamPad: 2}
+	tests := []struct {
+		name     string
+		parts    Parts
+		center   int
+		length   int
+		seq      string
+		mask     []uint8
+		segments []uint8
+		offset   int
+	}{
+		// A cut before the sixth base puts that base at index 2.
+		{"inside", parts, 6, 4, "CCCC", []uint8{1, 1, 1, 1}, []uint8{1, 1, 1, 1}, 4},
+		{"odd length", parts, 6, 5, "CCCCG", []uint8{1, 1, 1, 1, 1}, []uint8{1, 1, 1, 1, 2}, 4},
+		{"before the start", parts, 2, 8, "NNAAAACC", []uint8{0, 0, 1, 1, 1, 1, 1, 1}, []uint8{0, 0, 0, 0, 0, 0, 1, 1}, -2},
+		{"past the end", parts, 11, 6, "GGGGNN", []uint8{1, 1, 1, 1, 0, 0}, []uint8{2, 2, 2, 2, 2, 2}, 8},
+		{"whole", parts, 6, 12, "AAAACCCCGGGG", []uint8{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, []uint8{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2}, 0},
+		// Pad characters already in the flanks are masked like the
+		// positions beyond them.
+		{"padded flanks", padded, 6, 14, "NNNAACCCCGGNNN", []uint8{0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0}, []uint8{0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 2}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.parts
+			p.Center = tt.center
+			w := Cut(p, tt.length)
+			if w.Sequence != tt.seq || w.Offset != tt.offset {
+				t.Errorf("got %q at %d, want %q at %d", w.Sequence, w.Offset, tt.seq, tt.offset)
+			}
+			if !reflect.DeepEqual(w.Mask, tt.mask) {
+				t.Errorf("got mask %v, want %v", w.Mask, tt.mask)
+			}
+			if !reflect.DeepEqual(w.Segments, tt.segments) {
+				t.Errorf("got segments %v, want %v", w.Segments, tt.segments)
+			}
+		})
+	}
+}